@@ -0,0 +1,109 @@
+// Package main provides the sorobangen CLI: a code generator that turns
+// a Soroban contract's SCSpecEntry metadata into a typed origin
+// processor layered on contract-invocation's Origin.
+//
+// Usage:
+//
+//	# From a compiled contract's Wasm binary
+//	sorobangen --wasm usdc.wasm --pkg usdctyped --out usdctyped_gen.go
+//
+//	# From a JSON export of the spec entries (for dev/testing without a
+//	# real Wasm build)
+//	sorobangen --spec-json usdc.spec.json --pkg usdctyped --out usdctyped_gen.go
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/stellar/go-stellar-sdk/xdr"
+	"github.com/withObsrvr/nebu-processor-registry/pkg/contractspec"
+	"github.com/withObsrvr/nebu-processor-registry/pkg/sorobangen"
+)
+
+const version = "0.1.0"
+
+var (
+	wasmPath     string
+	specJSONPath string
+	pkgName      string
+	outPath      string
+)
+
+func main() {
+	cmd := &cobra.Command{
+		Use:     "sorobangen",
+		Short:   "Generate a typed Soroban contract processor from its SCSpecEntry metadata",
+		Version: version,
+		RunE:    run,
+	}
+	addFlags(cmd)
+
+	if err := cmd.Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+func addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&wasmPath, "wasm", "", "Path to the compiled contract's Wasm binary (reads its contractspecv0 section)")
+	cmd.Flags().StringVar(&specJSONPath, "spec-json", "", "Path to a JSON array of xdr.ScSpecEntry values, as an alternative to --wasm")
+	cmd.Flags().StringVar(&pkgName, "pkg", "", "Go package name for the generated file (required)")
+	cmd.Flags().StringVar(&outPath, "out", "", "Output file path (defaults to stdout)")
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	if pkgName == "" {
+		return fmt.Errorf("--pkg is required")
+	}
+	if (wasmPath == "") == (specJSONPath == "") {
+		return fmt.Errorf("exactly one of --wasm or --spec-json is required")
+	}
+
+	entries, err := loadSpecEntries()
+	if err != nil {
+		return err
+	}
+
+	spec, err := sorobangen.ParseSpec(entries, pkgName)
+	if err != nil {
+		return fmt.Errorf("parsing spec: %w", err)
+	}
+
+	source, err := sorobangen.Generate(spec)
+	if err != nil {
+		return fmt.Errorf("generating source: %w", err)
+	}
+
+	if outPath == "" {
+		_, err = os.Stdout.Write(source)
+		return err
+	}
+	return os.WriteFile(outPath, source, 0o644)
+}
+
+func loadSpecEntries() ([]xdr.ScSpecEntry, error) {
+	if wasmPath != "" {
+		wasm, err := os.ReadFile(wasmPath)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", wasmPath, err)
+		}
+		entries, err := contractspec.ExtractSpecEntries(wasm)
+		if err != nil {
+			return nil, fmt.Errorf("extracting spec from %s: %w", wasmPath, err)
+		}
+		return entries, nil
+	}
+
+	data, err := os.ReadFile(specJSONPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", specJSONPath, err)
+	}
+	var entries []xdr.ScSpecEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", specJSONPath, err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,196 @@
+// Package batch provides a batching and backpressure helper for sink
+// processors. json-file-sink and postgres-sink each flush on their own
+// schedule (every event, or every BatchSize rows); this package extracts the
+// common size/time-bounded batching and bounded-channel backpressure so new
+// sinks don't have to reimplement it.
+package batch
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Config controls when a Batcher flushes and how much work it allows to be
+// queued before Add blocks.
+type Config struct {
+	// MaxSize flushes once the batch reaches this many events. 0 disables
+	// the size-based trigger.
+	MaxSize int
+
+	// MaxBytes flushes once the batch's accumulated Sizeof(event) estimate
+	// reaches this many bytes. 0 disables the byte-based trigger.
+	MaxBytes int
+
+	// FlushInterval flushes on a ticker even if MaxSize/MaxBytes haven't
+	// been reached, bounding end-to-end latency for low-volume streams.
+	FlushInterval time.Duration
+
+	// MaxInFlight bounds the number of events buffered ahead of the flush
+	// goroutine; Add blocks once this many events are queued, applying
+	// backpressure to the event source.
+	MaxInFlight int
+}
+
+// FlushFunc writes a batch of events to the sink's destination.
+type FlushFunc func(batch []map[string]interface{}) error
+
+// Batcher accumulates events and calls a FlushFunc when Config's size, byte,
+// or time thresholds are reached, or when Close is called.
+type Batcher struct {
+	cfg   Config
+	flush FlushFunc
+
+	mu      sync.Mutex
+	pending []map[string]interface{}
+	bytes   int
+	sem     chan struct{}
+
+	ticker *time.Ticker
+	stop   chan struct{}
+	done   chan struct{}
+
+	flushErr error
+}
+
+// New creates a Batcher that calls flush whenever a threshold in cfg is
+// reached. Callers must call Close to drain and stop the background ticker.
+func New(cfg Config, flush FlushFunc) *Batcher {
+	if cfg.MaxInFlight <= 0 {
+		cfg.MaxInFlight = cfg.MaxSize
+	}
+	if cfg.MaxInFlight <= 0 {
+		cfg.MaxInFlight = 1000
+	}
+
+	b := &Batcher{
+		cfg:   cfg,
+		flush: flush,
+		sem:   make(chan struct{}, cfg.MaxInFlight),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+
+	if cfg.FlushInterval > 0 {
+		b.ticker = time.NewTicker(cfg.FlushInterval)
+		go b.tickLoop()
+	} else {
+		close(b.done)
+	}
+
+	return b
+}
+
+func (b *Batcher) tickLoop() {
+	defer close(b.done)
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-b.ticker.C:
+			if err := b.Flush(); err != nil {
+				b.mu.Lock()
+				b.flushErr = err
+				b.mu.Unlock()
+			}
+		}
+	}
+}
+
+// Add appends event to the current batch, flushing first if MaxSize or
+// MaxBytes would be exceeded. It blocks if MaxInFlight events are already
+// queued ahead of the flush goroutine.
+func (b *Batcher) Add(event map[string]interface{}) error {
+	b.mu.Lock()
+	if err := b.flushErr; err != nil {
+		b.mu.Unlock()
+		return fmt.Errorf("previous flush failed: %w", err)
+	}
+
+	size := estimateSize(event)
+	needsFlush := (b.cfg.MaxSize > 0 && len(b.pending)+1 > b.cfg.MaxSize) ||
+		(b.cfg.MaxBytes > 0 && b.bytes+size > b.cfg.MaxBytes)
+
+	var toFlush []map[string]interface{}
+	if needsFlush && len(b.pending) > 0 {
+		toFlush = b.pending
+		b.pending = nil
+		b.bytes = 0
+	}
+	b.mu.Unlock()
+
+	var flushErr error
+	if toFlush != nil {
+		flushErr = b.flushBatch(toFlush)
+	}
+
+	b.sem <- struct{}{}
+	b.mu.Lock()
+	b.pending = append(b.pending, event)
+	b.bytes += size
+	b.mu.Unlock()
+
+	return flushErr
+}
+
+// Flush writes out whatever is currently pending, even if below threshold.
+func (b *Batcher) Flush() error {
+	b.mu.Lock()
+	toFlush := b.pending
+	b.pending = nil
+	b.bytes = 0
+	b.mu.Unlock()
+
+	if len(toFlush) == 0 {
+		return nil
+	}
+	return b.flushBatch(toFlush)
+}
+
+func (b *Batcher) flushBatch(toFlush []map[string]interface{}) error {
+	err := b.flush(toFlush)
+	for range toFlush {
+		<-b.sem
+	}
+	return err
+}
+
+// Close stops the flush ticker and flushes any remaining buffered events.
+// It must be called (typically from a SIGTERM/SIGINT handler) so the last
+// partial batch isn't lost.
+func (b *Batcher) Close() error {
+	if b.ticker != nil {
+		b.ticker.Stop()
+		close(b.stop)
+		<-b.done
+	}
+	return b.Flush()
+}
+
+// estimateSize gives a cheap approximation of an event's marshaled size
+// without paying for a full json.Marshal on every Add call.
+func estimateSize(event map[string]interface{}) int {
+	size := 2 // surrounding braces
+	for k, v := range event {
+		size += len(k) + 6 // key + quotes + colon + comma
+		size += estimateValueSize(v)
+	}
+	return size
+}
+
+func estimateValueSize(v interface{}) int {
+	switch val := v.(type) {
+	case string:
+		return len(val) + 2
+	case map[string]interface{}:
+		return estimateSize(val)
+	case []interface{}:
+		total := 2
+		for _, item := range val {
+			total += estimateValueSize(item)
+		}
+		return total
+	default:
+		return 8
+	}
+}
@@ -0,0 +1,113 @@
+package checkpoint
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// FileStore persists checkpoints as one file per (processor, shard) under a
+// directory, writing via a temp file + atomic rename so a crash mid-write
+// never leaves a truncated checkpoint for the next run to read.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a FileStore rooted at dir, creating it if needed.
+func NewFileStore(dir string) (*FileStore, error) {
+	if dir == "" {
+		dir = "."
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create checkpoint directory %s: %w", dir, err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (s *FileStore) path(processor, shard string) string {
+	name := sanitize(processor) + "__" + sanitize(shard) + ".checkpoint"
+	return filepath.Join(s.dir, name)
+}
+
+// Load implements Store.
+func (s *FileStore) Load(processor, shard string) (uint32, error) {
+	data, err := os.ReadFile(s.path(processor, shard))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	ledger, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("corrupt checkpoint file %s: %w", s.path(processor, shard), err)
+	}
+	return uint32(ledger), nil
+}
+
+// Save implements Store.
+func (s *FileStore) Save(processor, shard string, ledger uint32) error {
+	final := s.path(processor, shard)
+	tmp := final + ".tmp"
+
+	if err := os.WriteFile(tmp, []byte(strconv.FormatUint(uint64(ledger), 10)), 0o644); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, final); err != nil {
+		return fmt.Errorf("failed to commit checkpoint: %w", err)
+	}
+	return nil
+}
+
+func sanitize(s string) string {
+	return strings.NewReplacer("/", "_", ":", "_", " ", "_").Replace(s)
+}
+
+func (s *FileStore) positionPath(processor, shard string) string {
+	name := sanitize(processor) + "__" + sanitize(shard) + ".position"
+	return filepath.Join(s.dir, name)
+}
+
+// LoadPosition implements PositionStore.
+func (s *FileStore) LoadPosition(processor, shard string) (Position, error) {
+	path := s.positionPath(processor, shard)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Position{}, nil
+	}
+	if err != nil {
+		return Position{}, fmt.Errorf("failed to read checkpoint position: %w", err)
+	}
+
+	parts := strings.Split(strings.TrimSpace(string(data)), ":")
+	if len(parts) != 3 {
+		return Position{}, fmt.Errorf("corrupt checkpoint position file %s", path)
+	}
+	var pos Position
+	for i, dst := range []*uint32{&pos.Ledger, &pos.TxIndex, &pos.OpIndex} {
+		v, err := strconv.ParseUint(parts[i], 10, 32)
+		if err != nil {
+			return Position{}, fmt.Errorf("corrupt checkpoint position file %s: %w", path, err)
+		}
+		*dst = uint32(v)
+	}
+	return pos, nil
+}
+
+// SavePosition implements PositionStore.
+func (s *FileStore) SavePosition(processor, shard string, pos Position) error {
+	final := s.positionPath(processor, shard)
+	tmp := final + ".tmp"
+
+	line := fmt.Sprintf("%d:%d:%d", pos.Ledger, pos.TxIndex, pos.OpIndex)
+	if err := os.WriteFile(tmp, []byte(line), 0o644); err != nil {
+		return fmt.Errorf("failed to write checkpoint position: %w", err)
+	}
+	if err := os.Rename(tmp, final); err != nil {
+		return fmt.Errorf("failed to commit checkpoint position: %w", err)
+	}
+	return nil
+}
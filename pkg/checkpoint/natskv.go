@@ -0,0 +1,79 @@
+package checkpoint
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSKVStore persists checkpoints in a JetStream key-value bucket, the
+// natural choice when a processor's pipeline already depends on NATS
+// (nats-source, nats-sink) and shouldn't need a second stateful dependency
+// just for checkpointing.
+type NATSKVStore struct {
+	nc *nats.Conn
+	kv nats.KeyValue
+}
+
+// NewNATSKVStore connects to NATS at url and binds to (creating if needed)
+// a JetStream KV bucket named bucket.
+func NewNATSKVStore(url, bucket string) (*NATSKVStore, error) {
+	nc, err := nats.Connect(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS at %s: %w", url, err)
+	}
+
+	js, err := nc.JetStream()
+	if err != nil {
+		nc.Close()
+		return nil, fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+
+	kv, err := js.KeyValue(bucket)
+	if err == nats.ErrBucketNotFound {
+		kv, err = js.CreateKeyValue(&nats.KeyValueConfig{Bucket: bucket})
+	}
+	if err != nil {
+		nc.Close()
+		return nil, fmt.Errorf("failed to open checkpoint bucket %s: %w", bucket, err)
+	}
+
+	return &NATSKVStore{nc: nc, kv: kv}, nil
+}
+
+func (s *NATSKVStore) key(processor, shard string) string {
+	return fmt.Sprintf("%s.%s", processor, shard)
+}
+
+// Load implements Store.
+func (s *NATSKVStore) Load(processor, shard string) (uint32, error) {
+	entry, err := s.kv.Get(s.key(processor, shard))
+	if err == nats.ErrKeyNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to load checkpoint: %w", err)
+	}
+
+	ledger, err := strconv.ParseUint(string(entry.Value()), 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("corrupt checkpoint value %q: %w", entry.Value(), err)
+	}
+	return uint32(ledger), nil
+}
+
+// Save implements Store.
+func (s *NATSKVStore) Save(processor, shard string, ledger uint32) error {
+	_, err := s.kv.Put(s.key(processor, shard), []byte(strconv.FormatUint(uint64(ledger), 10)))
+	if err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	return nil
+}
+
+// Close releases the underlying NATS connection.
+func (s *NATSKVStore) Close() error {
+	s.nc.Close()
+	return nil
+}
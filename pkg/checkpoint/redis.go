@@ -0,0 +1,100 @@
+package checkpoint
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore persists checkpoints as plain string keys, letting multiple
+// processor instances (or a monitoring dashboard) share progress through a
+// Redis instance they already run.
+type RedisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore connects to a Redis server at the given redis:// URL.
+func NewRedisStore(url string) (*RedisStore, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis checkpoint store URL %q: %w", url, err)
+	}
+	return &RedisStore{client: redis.NewClient(opts)}, nil
+}
+
+func (s *RedisStore) key(processor, shard string) string {
+	return fmt.Sprintf("nebu:checkpoint:%s:%s", processor, shard)
+}
+
+func (s *RedisStore) positionKey(processor, shard string) string {
+	return fmt.Sprintf("nebu:checkpoint:position:%s:%s", processor, shard)
+}
+
+// Load implements Store.
+func (s *RedisStore) Load(processor, shard string) (uint32, error) {
+	val, err := s.client.Get(context.Background(), s.key(processor, shard)).Result()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to load checkpoint: %w", err)
+	}
+
+	ledger, err := strconv.ParseUint(val, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("corrupt checkpoint value %q: %w", val, err)
+	}
+	return uint32(ledger), nil
+}
+
+// Save implements Store.
+func (s *RedisStore) Save(processor, shard string, ledger uint32) error {
+	err := s.client.Set(context.Background(), s.key(processor, shard), ledger, 0).Err()
+	if err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	return nil
+}
+
+// LoadPosition implements PositionStore.
+func (s *RedisStore) LoadPosition(processor, shard string) (Position, error) {
+	val, err := s.client.Get(context.Background(), s.positionKey(processor, shard)).Result()
+	if err == redis.Nil {
+		return Position{}, nil
+	}
+	if err != nil {
+		return Position{}, fmt.Errorf("failed to load checkpoint position: %w", err)
+	}
+
+	parts := strings.Split(val, ":")
+	if len(parts) != 3 {
+		return Position{}, fmt.Errorf("corrupt checkpoint position value %q", val)
+	}
+	var pos Position
+	for i, dst := range []*uint32{&pos.Ledger, &pos.TxIndex, &pos.OpIndex} {
+		v, err := strconv.ParseUint(parts[i], 10, 32)
+		if err != nil {
+			return Position{}, fmt.Errorf("corrupt checkpoint position value %q: %w", val, err)
+		}
+		*dst = uint32(v)
+	}
+	return pos, nil
+}
+
+// SavePosition implements PositionStore.
+func (s *RedisStore) SavePosition(processor, shard string, pos Position) error {
+	val := fmt.Sprintf("%d:%d:%d", pos.Ledger, pos.TxIndex, pos.OpIndex)
+	err := s.client.Set(context.Background(), s.positionKey(processor, shard), val, 0).Err()
+	if err != nil {
+		return fmt.Errorf("failed to save checkpoint position: %w", err)
+	}
+	return nil
+}
+
+// Close releases the Redis client's connections.
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,110 @@
+package checkpoint
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteStore persists checkpoints in a single-table SQLite database,
+// useful when a processor's checkpoints should travel alongside other
+// local state without standing up Redis or NATS.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore opens (creating if needed) a SQLite database at path and
+// ensures the checkpoints table exists.
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite checkpoint db %s: %w", path, err)
+	}
+
+	const schema = `
+CREATE TABLE IF NOT EXISTS checkpoints (
+	processor TEXT NOT NULL,
+	shard     TEXT NOT NULL,
+	ledger    INTEGER NOT NULL,
+	PRIMARY KEY (processor, shard)
+);
+CREATE TABLE IF NOT EXISTS checkpoint_positions (
+	processor TEXT NOT NULL,
+	shard     TEXT NOT NULL,
+	ledger    INTEGER NOT NULL,
+	tx_index  INTEGER NOT NULL,
+	op_index  INTEGER NOT NULL,
+	PRIMARY KEY (processor, shard)
+);
+`
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create checkpoints table: %w", err)
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+// Load implements Store.
+func (s *SQLiteStore) Load(processor, shard string) (uint32, error) {
+	var ledger int64
+	err := s.db.QueryRow(
+		`SELECT ledger FROM checkpoints WHERE processor = ? AND shard = ?`,
+		processor, shard,
+	).Scan(&ledger)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to load checkpoint: %w", err)
+	}
+	return uint32(ledger), nil
+}
+
+// Save implements Store.
+func (s *SQLiteStore) Save(processor, shard string, ledger uint32) error {
+	_, err := s.db.Exec(
+		`INSERT INTO checkpoints (processor, shard, ledger) VALUES (?, ?, ?)
+		 ON CONFLICT (processor, shard) DO UPDATE SET ledger = excluded.ledger`,
+		processor, shard, ledger,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	return nil
+}
+
+// LoadPosition implements PositionStore.
+func (s *SQLiteStore) LoadPosition(processor, shard string) (Position, error) {
+	var pos Position
+	err := s.db.QueryRow(
+		`SELECT ledger, tx_index, op_index FROM checkpoint_positions WHERE processor = ? AND shard = ?`,
+		processor, shard,
+	).Scan(&pos.Ledger, &pos.TxIndex, &pos.OpIndex)
+	if err == sql.ErrNoRows {
+		return Position{}, nil
+	}
+	if err != nil {
+		return Position{}, fmt.Errorf("failed to load checkpoint position: %w", err)
+	}
+	return pos, nil
+}
+
+// SavePosition implements PositionStore.
+func (s *SQLiteStore) SavePosition(processor, shard string, pos Position) error {
+	_, err := s.db.Exec(
+		`INSERT INTO checkpoint_positions (processor, shard, ledger, tx_index, op_index) VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT (processor, shard) DO UPDATE SET ledger = excluded.ledger, tx_index = excluded.tx_index, op_index = excluded.op_index`,
+		processor, shard, pos.Ledger, pos.TxIndex, pos.OpIndex,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save checkpoint position: %w", err)
+	}
+	return nil
+}
+
+// Close releases the underlying database handle.
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,140 @@
+// Package contractspec loads Soroban contract spec entries (the same
+// SCSpecEntry metadata Soroban CLI/SDKs embed alongside a contract's Wasm,
+// via the "contractspecv0" custom section) and uses the event specs within
+// to decode raw ScVal topic/data trees into named fields, so consumers can
+// write code against e.g. fields["to"] instead of TopicDecoded[2].
+package contractspec
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+// DataFormat mirrors xdr.ScSpecEventDataFormat: whether an event's data
+// payload is a single value, a vector, or a map of named fields.
+type DataFormat int
+
+const (
+	DataFormatSingleValue DataFormat = iota
+	DataFormatVec
+	DataFormatMap
+)
+
+// EventSpec describes one contract event's shape, compiled from its
+// xdr.ScSpecEventV0 entry.
+type EventSpec struct {
+	// Name is the event's fully-qualified name, e.g. "transfer".
+	Name string
+	// PrefixTopicCount is the number of fixed discriminant topics the spec
+	// declares (ev.PrefixTopics) — normally 1, the event-name symbol
+	// itself, but a spec may fix more than one leading topic.
+	PrefixTopicCount int
+	// TopicParams names the indexed topics after the prefix, in positional
+	// order.
+	TopicParams []string
+	// DataFormat selects how DataParams below map onto the event's data
+	// ScVal.
+	DataFormat DataFormat
+	// DataParams names the data fields: one entry for DataFormatSingleValue
+	// or DataFormatVec (positional), unused for DataFormatMap (the map's
+	// own keys are used instead).
+	DataParams []string
+}
+
+// topicArity is the number of topics an event with this spec produces:
+// the fixed prefix topics plus one per indexed topic param.
+func (s EventSpec) topicArity() int {
+	return s.PrefixTopicCount + len(s.TopicParams)
+}
+
+// SpecRegistry holds loaded contract specs keyed by contract ID and, within
+// a contract, by the event's discriminant symbol (its first topic), so
+// buildContractEvent can look up a matching spec with one map read before
+// attempting typed decoding.
+type SpecRegistry struct {
+	mu     sync.RWMutex
+	events map[string]map[string]EventSpec // contractID -> symbol -> spec
+}
+
+// NewSpecRegistry creates an empty registry. A processor with no specs
+// registered always takes the raw-event-only fallback path.
+func NewSpecRegistry() *SpecRegistry {
+	return &SpecRegistry{events: make(map[string]map[string]EventSpec)}
+}
+
+// LoadSpec parses entries (as read from a contract's contractspecv0
+// section) and registers every event spec found under contractID,
+// replacing any specs previously registered for that contract.
+func (r *SpecRegistry) LoadSpec(contractID string, entries []xdr.ScSpecEntry) error {
+	bysymbol := make(map[string]EventSpec)
+
+	for _, entry := range entries {
+		if entry.Kind != xdr.ScSpecEntryKindScSpecEntryEventV0 {
+			continue
+		}
+		ev := entry.MustEventV0()
+		if len(ev.PrefixTopics) == 0 {
+			return fmt.Errorf("event spec %q has no prefix topics", ev.Name)
+		}
+
+		spec := EventSpec{
+			Name:             string(ev.Name),
+			PrefixTopicCount: len(ev.PrefixTopics),
+			TopicParams:      paramNames(ev.Params, xdr.ScSpecEventParamLocationScSpecEventParamLocationTopicList),
+			DataParams:       paramNames(ev.Params, xdr.ScSpecEventParamLocationScSpecEventParamLocationData),
+		}
+		switch ev.DataFormat {
+		case xdr.ScSpecEventDataFormatScSpecEventDataFormatVec:
+			spec.DataFormat = DataFormatVec
+		case xdr.ScSpecEventDataFormatScSpecEventDataFormatMap:
+			spec.DataFormat = DataFormatMap
+		default:
+			spec.DataFormat = DataFormatSingleValue
+		}
+
+		discriminant := string(ev.PrefixTopics[0])
+		bysymbol[discriminant] = spec
+	}
+
+	r.mu.Lock()
+	r.events[contractID] = bysymbol
+	r.mu.Unlock()
+	return nil
+}
+
+// paramNames returns, in declaration order, the names of ev's params whose
+// Location matches loc.
+func paramNames(params []xdr.ScSpecEventParamV0, loc xdr.ScSpecEventParamLocation) []string {
+	var names []string
+	for _, p := range params {
+		if p.Location == loc {
+			names = append(names, string(p.Name))
+		}
+	}
+	return names
+}
+
+// Lookup returns the event spec registered for contractID whose
+// discriminant symbol is symbol (the event's first topic), if any.
+func (r *SpecRegistry) Lookup(contractID, symbol string) (EventSpec, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	byContract, ok := r.events[contractID]
+	if !ok {
+		return EventSpec{}, false
+	}
+	spec, ok := byContract[symbol]
+	return spec, ok
+}
+
+// HasContract reports whether any spec is registered for contractID, so
+// callers can skip discriminant lookups entirely for unregistered
+// contracts.
+func (r *SpecRegistry) HasContract(contractID string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.events[contractID]
+	return ok
+}
@@ -0,0 +1,118 @@
+package contractspec
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+const specSectionName = "contractspecv0"
+
+// ExtractSpecEntries reads the "contractspecv0" custom Wasm section from a
+// compiled Soroban contract binary and decodes its contents into the
+// []xdr.ScSpecEntry stream LoadSpec expects — the same metadata `stellar
+// contract info interface` reads to print a contract's ABI.
+func ExtractSpecEntries(wasm []byte) ([]xdr.ScSpecEntry, error) {
+	section, err := extractCustomSection(wasm, specSectionName)
+	if err != nil {
+		return nil, err
+	}
+	return decodeSpecEntryStream(section)
+}
+
+// extractCustomSection walks a Wasm binary's section headers looking for
+// a custom section (id 0) named name, returning its payload (the bytes
+// after the section's own name field).
+func extractCustomSection(wasm []byte, name string) ([]byte, error) {
+	if len(wasm) < 8 || !bytes.Equal(wasm[:4], []byte("\x00asm")) {
+		return nil, fmt.Errorf("contractspec: not a Wasm binary")
+	}
+
+	r := bytes.NewReader(wasm[8:])
+	for {
+		id, err := r.ReadByte()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("contractspec: reading section id: %w", err)
+		}
+
+		size, err := readULEB128(r)
+		if err != nil {
+			return nil, fmt.Errorf("contractspec: reading section size: %w", err)
+		}
+
+		body := make([]byte, size)
+		if _, err := io.ReadFull(r, body); err != nil {
+			return nil, fmt.Errorf("contractspec: reading section body: %w", err)
+		}
+
+		if id != 0 {
+			continue // not a custom section
+		}
+
+		sectionName, payload, err := readWasmString(body)
+		if err != nil {
+			return nil, err
+		}
+		if sectionName == name {
+			return payload, nil
+		}
+	}
+
+	return nil, fmt.Errorf("contractspec: no %q section found", name)
+}
+
+// readWasmString reads a Wasm "name" value (a ULEB128 length followed by
+// that many UTF-8 bytes) from the front of b, returning the decoded string
+// and the remaining, unconsumed bytes.
+func readWasmString(b []byte) (string, []byte, error) {
+	r := bytes.NewReader(b)
+	n, err := readULEB128(r)
+	if err != nil {
+		return "", nil, fmt.Errorf("contractspec: reading name length: %w", err)
+	}
+	nameBytes := make([]byte, n)
+	if _, err := io.ReadFull(r, nameBytes); err != nil {
+		return "", nil, fmt.Errorf("contractspec: reading name: %w", err)
+	}
+	return string(nameBytes), b[len(b)-r.Len():], nil
+}
+
+func readULEB128(r io.ByteReader) (uint64, error) {
+	var result uint64
+	var shift uint
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		result |= uint64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			break
+		}
+		shift += 7
+	}
+	return result, nil
+}
+
+// decodeSpecEntryStream decodes a back-to-back stream of XDR-encoded
+// ScSpecEntry values, the form the contractspecv0 section stores them in:
+// one XDR union per entry, with no length prefix between them.
+func decodeSpecEntryStream(data []byte) ([]xdr.ScSpecEntry, error) {
+	var entries []xdr.ScSpecEntry
+
+	r := bytes.NewReader(data)
+	for r.Len() > 0 {
+		var entry xdr.ScSpecEntry
+		if _, err := xdr.Unmarshal(r, &entry); err != nil {
+			return nil, fmt.Errorf("contractspec: decoding spec entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
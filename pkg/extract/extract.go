@@ -0,0 +1,99 @@
+// Package extract compiles a list of jq expressions once and runs them
+// against an event in order, returning the first non-empty match. It
+// replaces the hand-rolled type-switch ladders that used to be duplicated
+// across postgres-sink's extractEventType and amount_filter.FilterEvent's
+// oneof probing, so adapting either processor to a new protobuf oneof or a
+// custom JQ-shaped event is a flag change instead of a recompile.
+package extract
+
+import (
+	"fmt"
+
+	"github.com/itchyny/gojq"
+)
+
+// Extractor runs a fixed, ordered list of compiled jq expressions against
+// an event and returns the first one that yields a non-null, non-empty
+// result.
+type Extractor struct {
+	exprs    []string
+	programs []*gojq.Code
+}
+
+// New compiles exprs (jq syntax, e.g. ".event_type" or
+// `if .transfer then "transfer" else empty end`) once so Extract can run
+// the hot path without reparsing.
+func New(exprs []string) (*Extractor, error) {
+	e := &Extractor{exprs: exprs}
+	for _, expr := range exprs {
+		query, err := gojq.Parse(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expression %q: %w", expr, err)
+		}
+		code, err := gojq.Compile(query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile expression %q: %w", expr, err)
+		}
+		e.programs = append(e.programs, code)
+	}
+	return e, nil
+}
+
+// MustNew is like New but panics on a compile error, for package-level
+// default expression lists that are controlled by this repo rather than
+// user input.
+func MustNew(exprs []string) *Extractor {
+	e, err := New(exprs)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// Extract runs each compiled expression against event in order and
+// returns the first non-null, non-"" result, along with the index of the
+// expression that produced it. ok is false if no expression matched.
+func (e *Extractor) Extract(event map[string]interface{}) (value interface{}, index int, ok bool) {
+	for i, code := range e.programs {
+		iter := code.Run(event)
+		v, hasNext := iter.Next()
+		if !hasNext {
+			continue
+		}
+		if _, isErr := v.(error); isErr {
+			continue
+		}
+		if v == nil {
+			continue
+		}
+		if s, isStr := v.(string); isStr && s == "" {
+			continue
+		}
+		return v, i, true
+	}
+	return nil, -1, false
+}
+
+// ExtractString is a convenience wrapper for callers that only want a
+// string result (like postgres-sink's event type), discarding non-string
+// matches.
+func (e *Extractor) ExtractString(event map[string]interface{}) (string, bool) {
+	v, _, ok := e.Extract(event)
+	if !ok {
+		return "", false
+	}
+	s, isStr := v.(string)
+	return s, isStr
+}
+
+// ExtractMap is a convenience wrapper for callers that want a nested
+// object result (like amount-filter's transfer/mint/burn payload),
+// discarding non-map matches.
+func (e *Extractor) ExtractMap(event map[string]interface{}) (map[string]interface{}, bool) {
+	v, _, ok := e.Extract(event)
+	if !ok {
+		return nil, false
+	}
+	m, isMap := v.(map[string]interface{})
+	return m, isMap
+}
@@ -0,0 +1,42 @@
+package extract
+
+import "testing"
+
+// oneofTypeSwitch is the hand-rolled ladder Extract replaces in
+// amount_filter.FilterEvent, kept here only as a baseline for
+// BenchmarkExtract_vs_TypeSwitch.
+func oneofTypeSwitch(event map[string]interface{}) (map[string]interface{}, bool) {
+	for _, t := range []string{"transfer", "mint", "burn", "clawback", "fee"} {
+		if v, ok := event[t].(map[string]interface{}); ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func benchEvent() map[string]interface{} {
+	return map[string]interface{}{
+		"fee": map[string]interface{}{
+			"amount": "100",
+		},
+	}
+}
+
+func BenchmarkTypeSwitch(b *testing.B) {
+	event := benchEvent()
+	for i := 0; i < b.N; i++ {
+		if _, ok := oneofTypeSwitch(event); !ok {
+			b.Fatal("expected match")
+		}
+	}
+}
+
+func BenchmarkExtract(b *testing.B) {
+	e := MustNew([]string{".transfer", ".mint", ".burn", ".clawback", ".fee"})
+	event := benchEvent()
+	for i := 0; i < b.N; i++ {
+		if _, ok := e.ExtractMap(event); !ok {
+			b.Fatal("expected match")
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package filter
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LabeledEntry is one row of a labeled list file: an address/asset code
+// plus the cohort label it should be tagged with on a match.
+type LabeledEntry struct {
+	Address string `yaml:"address" json:"address"`
+	Label   string `yaml:"label" json:"label"`
+}
+
+// LoadLabeledFile reads a YAML or JSON array of LabeledEntry values from
+// path and installs it as the current list contents via SetLabeled. YAML
+// is a superset of JSON, so either format parses through the same
+// decoder.
+func (l *List) LoadLabeledFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to open labeled list file %s: %w", path, err)
+	}
+
+	var entries []LabeledEntry
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("failed to parse labeled list file %s: %w", path, err)
+	}
+
+	labeled := make(map[string]string, len(entries))
+	for _, e := range entries {
+		labeled[e.Address] = e.Label
+	}
+	l.SetLabeled(labeled)
+	return nil
+}
+
+// AdminHandler returns an http.Handler that lets an operator mutate l at
+// runtime without restarting the pipeline:
+//
+//	POST   basePath           {"address": "...", "label": "..."}  add/update an entry
+//	DELETE basePath/{address}                                      remove an entry
+//
+// Mutations apply directly to the in-memory sync.Map and take effect on
+// the very next event; they are not persisted back to whatever file or
+// database the list was originally loaded from.
+//
+// token must be non-empty: every request must present it as
+// "Authorization: Bearer <token>" or it's rejected with 401. Lists served
+// this way typically track exchanges, treasuries, or sanctioned addresses,
+// so an unauthenticated mutation endpoint would let any network client
+// tamper with a compliance-sensitive list.
+func (l *List) AdminHandler(basePath, token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(basePath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var entry LabeledEntry
+		if err := json.NewDecoder(r.Body).Decode(&entry); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+			return
+		}
+		if entry.Address == "" {
+			http.Error(w, "address is required", http.StatusBadRequest)
+			return
+		}
+		l.AddEntry(entry.Address, entry.Label)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc(basePath+"/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		addr := strings.TrimPrefix(r.URL.Path, basePath+"/")
+		if addr == "" {
+			http.Error(w, "address is required", http.StatusBadRequest)
+			return
+		}
+		l.RemoveEntry(addr)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	return requireBearerToken(token, mux)
+}
+
+// requireBearerToken wraps next so every request must present
+// "Authorization: Bearer <token>", comparing in constant time so a
+// response's timing can't be used to guess the token byte by byte.
+func requireBearerToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const prefix = "Bearer "
+		got := r.Header.Get("Authorization")
+		if !strings.HasPrefix(got, prefix) ||
+			subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(got, prefix)), []byte(token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,50 @@
+package filter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminHandler_RequiresBearerToken(t *testing.T) {
+	l := NewList("", ListAllow, nil, nil)
+	handler := l.AdminHandler("/watchlist", "s3cret")
+
+	body := strings.NewReader(`{"address":"GABC","label":"exchange"}`)
+	req := httptest.NewRequest(http.MethodPost, "/watchlist", body)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	_, ok := l.Label("gabc")
+	assert.False(t, ok, "unauthenticated request must not mutate the list")
+}
+
+func TestAdminHandler_WrongTokenRejected(t *testing.T) {
+	l := NewList("", ListAllow, nil, nil)
+	handler := l.AdminHandler("/watchlist", "s3cret")
+
+	body := strings.NewReader(`{"address":"GABC","label":"exchange"}`)
+	req := httptest.NewRequest(http.MethodPost, "/watchlist", body)
+	req.Header.Set("Authorization", "Bearer wrong")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestAdminHandler_CorrectTokenAccepted(t *testing.T) {
+	l := NewList("", ListAllow, nil, nil)
+	handler := l.AdminHandler("/watchlist", "s3cret")
+
+	body := strings.NewReader(`{"address":"GABC","label":"exchange"}`)
+	req := httptest.NewRequest(http.MethodPost, "/watchlist", body)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	label, ok := l.Label("gabc")
+	assert.True(t, ok)
+	assert.Equal(t, "exchange", label)
+}
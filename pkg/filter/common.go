@@ -0,0 +1,123 @@
+package filter
+
+import (
+	"strconv"
+	"strings"
+)
+
+// getNestedValue retrieves a value from a nested map using dot notation,
+// mirroring the helper duplicated across dedup and nats-sink.
+func getNestedValue(event map[string]interface{}, key string) (interface{}, bool) {
+	current := event
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		value, ok := current[part]
+		if !ok {
+			return nil, false
+		}
+		if i == len(parts)-1 {
+			return value, true
+		}
+		current, ok = value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+// AssetCodeFilter keeps only events whose asset code (protojson format,
+// either a native asset or an issued asset) matches Code.
+type AssetCodeFilter struct {
+	// Field is the dot-notation path to the event payload containing an
+	// "asset" object, e.g. "transfer" or "mint".
+	Field string
+	Code  string
+}
+
+func (f *AssetCodeFilter) Name() string { return "asset-code:" + f.Code }
+
+func (f *AssetCodeFilter) Apply(event map[string]interface{}) (bool, map[string]interface{}, error) {
+	eventData, ok := getNestedValue(event, f.Field)
+	if !ok {
+		return false, nil, nil
+	}
+	data, ok := eventData.(map[string]interface{})
+	if !ok {
+		return false, nil, nil
+	}
+
+	asset, ok := data["asset"].(map[string]interface{})
+	if !ok {
+		return false, nil, nil
+	}
+
+	if issued, ok := asset["issuedAsset"].(map[string]interface{}); ok {
+		code, _ := issued["assetCode"].(string)
+		if code != f.Code {
+			return false, nil, nil
+		}
+		return true, event, nil
+	}
+
+	if native, ok := asset["native"].(bool); ok && native {
+		if f.Code != "native" && f.Code != "XLM" {
+			return false, nil, nil
+		}
+		return true, event, nil
+	}
+
+	return false, nil, nil
+}
+
+// MinAmountFilter keeps only events whose amount field (a string, as
+// produced by protojson for int64 fields) is >= Min.
+type MinAmountFilter struct {
+	Field string // dot-notation path to the event payload, e.g. "transfer"
+	Min   int64
+}
+
+func (f *MinAmountFilter) Name() string { return "min-amount" }
+
+func (f *MinAmountFilter) Apply(event map[string]interface{}) (bool, map[string]interface{}, error) {
+	eventData, ok := getNestedValue(event, f.Field)
+	if !ok {
+		return false, nil, nil
+	}
+	data, ok := eventData.(map[string]interface{})
+	if !ok {
+		return false, nil, nil
+	}
+
+	amountStr, ok := data["amount"].(string)
+	if !ok {
+		return false, nil, nil
+	}
+
+	amount, err := strconv.ParseInt(amountStr, 10, 64)
+	if err != nil {
+		return false, nil, nil
+	}
+
+	if amount < f.Min {
+		return false, nil, nil
+	}
+	return true, event, nil
+}
+
+// EventTypeFilter keeps only events whose oneof-style type field (the first
+// of Types present as a top-level key) matches one of Types.
+type EventTypeFilter struct {
+	Types []string
+}
+
+func (f *EventTypeFilter) Name() string { return "event-type" }
+
+func (f *EventTypeFilter) Apply(event map[string]interface{}) (bool, map[string]interface{}, error) {
+	for _, t := range f.Types {
+		if _, ok := event[t]; ok {
+			return true, event, nil
+		}
+	}
+	return false, nil, nil
+}
@@ -0,0 +1,426 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Expr is a compiled boolean expression, e.g. `type=="transfer" &&
+// amount>1000 && asset.code=="USDC"`, that can be evaluated against an event
+// without the caller writing Go.
+type Expr struct {
+	root node
+	src  string
+}
+
+// CompileExpr parses a `--where`-style expression into an Expr.
+func CompileExpr(src string) (*Expr, error) {
+	p := &parser{tokens: tokenize(src)}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", src, err)
+	}
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("invalid expression %q: unexpected token %q", src, p.tokens[p.pos].text)
+	}
+	return &Expr{root: root, src: src}, nil
+}
+
+// Eval evaluates the expression against event and returns whether it matched.
+func (e *Expr) Eval(event map[string]interface{}) (bool, error) {
+	v, err := e.root.eval(event)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("expression %q did not evaluate to a boolean", e.src)
+	}
+	return b, nil
+}
+
+func (e *Expr) String() string { return e.src }
+
+// ExprFilter adapts a compiled Expr into a Filter: events that don't match
+// are dropped, matching events pass through unmodified.
+type ExprFilter struct {
+	name string
+	expr *Expr
+}
+
+// NewExprFilter compiles src and wraps it as a Filter.
+func NewExprFilter(name, src string) (*ExprFilter, error) {
+	expr, err := CompileExpr(src)
+	if err != nil {
+		return nil, err
+	}
+	return &ExprFilter{name: name, expr: expr}, nil
+}
+
+func (f *ExprFilter) Name() string { return f.name }
+
+func (f *ExprFilter) Apply(event map[string]interface{}) (bool, map[string]interface{}, error) {
+	keep, err := f.expr.Eval(event)
+	if err != nil {
+		return false, nil, err
+	}
+	if !keep {
+		return false, nil, nil
+	}
+	return true, event, nil
+}
+
+// --- expression AST ---
+
+type node interface {
+	eval(event map[string]interface{}) (interface{}, error)
+}
+
+type fieldNode struct{ path []string }
+
+func (n *fieldNode) eval(event map[string]interface{}) (interface{}, error) {
+	var current interface{} = event
+	for _, part := range n.path {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, nil // missing intermediate value resolves to nil, not an error
+		}
+		current = m[part]
+	}
+	return current, nil
+}
+
+type literalNode struct{ value interface{} }
+
+func (n *literalNode) eval(map[string]interface{}) (interface{}, error) { return n.value, nil }
+
+type notNode struct{ operand node }
+
+func (n *notNode) eval(event map[string]interface{}) (interface{}, error) {
+	v, err := n.operand.eval(event)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return nil, fmt.Errorf("! applied to non-boolean value %v", v)
+	}
+	return !b, nil
+}
+
+type logicalNode struct {
+	op          string // "&&" or "||"
+	left, right node
+}
+
+func (n *logicalNode) eval(event map[string]interface{}) (interface{}, error) {
+	l, err := n.left.eval(event)
+	if err != nil {
+		return nil, err
+	}
+	lb, ok := l.(bool)
+	if !ok {
+		return nil, fmt.Errorf("%s applied to non-boolean left operand %v", n.op, l)
+	}
+	if n.op == "&&" && !lb {
+		return false, nil
+	}
+	if n.op == "||" && lb {
+		return true, nil
+	}
+	r, err := n.right.eval(event)
+	if err != nil {
+		return nil, err
+	}
+	rb, ok := r.(bool)
+	if !ok {
+		return nil, fmt.Errorf("%s applied to non-boolean right operand %v", n.op, r)
+	}
+	return rb, nil
+}
+
+type compareNode struct {
+	op          string
+	left, right node
+}
+
+func (n *compareNode) eval(event map[string]interface{}) (interface{}, error) {
+	l, err := n.left.eval(event)
+	if err != nil {
+		return nil, err
+	}
+	r, err := n.right.eval(event)
+	if err != nil {
+		return nil, err
+	}
+	return compare(n.op, l, r)
+}
+
+func compare(op string, l, r interface{}) (bool, error) {
+	if op == "==" || op == "!=" {
+		eq := fmt.Sprint(l) == fmt.Sprint(r)
+		if l == nil || r == nil {
+			eq = l == r
+		}
+		if op == "!=" {
+			return !eq, nil
+		}
+		return eq, nil
+	}
+
+	lf, lok := toFloat(l)
+	rf, rok := toFloat(r)
+	if !lok || !rok {
+		return false, fmt.Errorf("operator %s requires numeric operands, got %v and %v", op, l, r)
+	}
+	switch op {
+	case "<":
+		return lf < rf, nil
+	case "<=":
+		return lf <= rf, nil
+	case ">":
+		return lf > rf, nil
+	case ">=":
+		return lf >= rf, nil
+	}
+	return false, fmt.Errorf("unknown operator %s", op)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// --- tokenizer ---
+
+type token struct {
+	kind string // "field", "string", "number", "op", "lparen", "rparen"
+	text string
+}
+
+func tokenize(src string) []token {
+	var tokens []token
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{"lparen", "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{"rparen", ")"})
+			i++
+		case c == '"' || c == '\'':
+			quote := c
+			j := i + 1
+			for j < len(src) && src[j] != quote {
+				j++
+			}
+			tokens = append(tokens, token{"string", src[i+1 : j]})
+			i = j + 1
+		case strings.HasPrefix(src[i:], "&&"):
+			tokens = append(tokens, token{"op", "&&"})
+			i += 2
+		case strings.HasPrefix(src[i:], "||"):
+			tokens = append(tokens, token{"op", "||"})
+			i += 2
+		case strings.HasPrefix(src[i:], "=="):
+			tokens = append(tokens, token{"op", "=="})
+			i += 2
+		case strings.HasPrefix(src[i:], "!="):
+			tokens = append(tokens, token{"op", "!="})
+			i += 2
+		case strings.HasPrefix(src[i:], "<="):
+			tokens = append(tokens, token{"op", "<="})
+			i += 2
+		case strings.HasPrefix(src[i:], ">="):
+			tokens = append(tokens, token{"op", ">="})
+			i += 2
+		case c == '<' || c == '>':
+			tokens = append(tokens, token{"op", string(c)})
+			i++
+		case c == '!':
+			tokens = append(tokens, token{"op", "!"})
+			i++
+		default:
+			j := i
+			for j < len(src) && isIdentByte(src[j]) {
+				j++
+			}
+			if j == i {
+				i++ // skip unrecognized character
+				continue
+			}
+			word := src[i:j]
+			if isNumber(word) {
+				tokens = append(tokens, token{"number", word})
+			} else {
+				tokens = append(tokens, token{"field", word})
+			}
+			i = j
+		}
+	}
+	return tokens
+}
+
+func isIdentByte(c byte) bool {
+	return c == '.' || c == '_' || c == '-' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
+
+// --- recursive descent parser ---
+//
+// Grammar (lowest to highest precedence):
+//   or    := and ("||" and)*
+//   and   := unary ("&&" unary)*
+//   unary := "!" unary | cmp
+//   cmp   := atom (("==" | "!=" | "<" | "<=" | ">" | ">=") atom)?
+//   atom  := field | string | number | "(" or ")"
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *parser) next() (token, bool) {
+	t, ok := p.peek()
+	if ok {
+		p.pos++
+	}
+	return t, ok
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t, ok := p.peek()
+		if !ok || t.kind != "op" || t.text != "||" {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{op: "||", left: left, right: right}
+	}
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t, ok := p.peek()
+		if !ok || t.kind != "op" || t.text != "&&" {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{op: "&&", left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if t, ok := p.peek(); ok && t.kind == "op" && t.text == "!" {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{operand: operand}, nil
+	}
+	return p.parseCompare()
+}
+
+func (p *parser) parseCompare() (node, error) {
+	left, err := p.parseAtom()
+	if err != nil {
+		return nil, err
+	}
+	t, ok := p.peek()
+	if !ok || t.kind != "op" {
+		return left, nil
+	}
+	switch t.text {
+	case "==", "!=", "<", "<=", ">", ">=":
+		p.next()
+		right, err := p.parseAtom()
+		if err != nil {
+			return nil, err
+		}
+		return &compareNode{op: t.text, left: left, right: right}, nil
+	}
+	return left, nil
+}
+
+func (p *parser) parseAtom() (node, error) {
+	t, ok := p.next()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	switch t.kind {
+	case "lparen":
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		closing, ok := p.next()
+		if !ok || closing.kind != "rparen" {
+			return nil, fmt.Errorf("expected closing parenthesis")
+		}
+		return inner, nil
+	case "string":
+		return &literalNode{value: t.text}, nil
+	case "number":
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", t.text)
+		}
+		return &literalNode{value: f}, nil
+	case "field":
+		switch t.text {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		}
+		return &fieldNode{path: strings.Split(t.text, ".")}, nil
+	}
+	return nil, fmt.Errorf("unexpected token %q", t.text)
+}
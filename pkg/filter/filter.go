@@ -0,0 +1,56 @@
+// Package filter provides a reusable filter chain that transform processors
+// can embed instead of hand-rolling their own conditional logic (see
+// usdc-filter, amount-filter, time-window for examples of the ad-hoc style
+// this package replaces).
+package filter
+
+import "fmt"
+
+// Filter inspects a single event and decides whether it should continue
+// through the pipeline, optionally rewriting it along the way.
+type Filter interface {
+	// Name identifies the filter, used in logs and metrics.
+	Name() string
+
+	// Apply evaluates the filter against event. If keep is false, the event
+	// is dropped and out is ignored. Otherwise out is the (possibly
+	// unmodified) event to pass to the next filter in the chain.
+	Apply(event map[string]interface{}) (keep bool, out map[string]interface{}, err error)
+}
+
+// Chain runs a sequence of Filters in order, short-circuiting on the first
+// filter that drops the event or returns an error.
+type Chain struct {
+	filters []Filter
+}
+
+// NewChain builds a Chain that runs filters in the given order.
+func NewChain(filters ...Filter) *Chain {
+	return &Chain{filters: filters}
+}
+
+// Apply runs the event through every filter in order. It returns keep=false
+// as soon as any filter drops the event.
+func (c *Chain) Apply(event map[string]interface{}) (keep bool, out map[string]interface{}, err error) {
+	current := event
+	for _, f := range c.filters {
+		keep, current, err = f.Apply(current)
+		if err != nil {
+			return false, nil, fmt.Errorf("filter %q: %w", f.Name(), err)
+		}
+		if !keep {
+			return false, nil, nil
+		}
+	}
+	return true, current, nil
+}
+
+// Names returns the ordered list of filter names in the chain, useful for
+// logging the active pipeline on startup.
+func (c *Chain) Names() []string {
+	names := make([]string, len(c.filters))
+	for i, f := range c.filters {
+		names[i] = f.Name()
+	}
+	return names
+}
@@ -0,0 +1,256 @@
+// Package ledgertime resolves a Stellar ledger's close time more
+// accurately than the fixed "genesis + ledgerSeq*5s" approximation
+// time-window used to rely on, which drifts by minutes over millions of
+// ledgers since protocol upgrades have changed close time and individual
+// ledgers vary. It prefers the closeTime already present in an event's
+// protojson meta, falls back to a local SQLite cache backfilled from
+// Horizon or stellar-core, and only falls back to the 5-second
+// approximation when neither is available.
+package ledgertime
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// StellarGenesisUnix is the Stellar network's genesis ledger close
+	// time (2015-07-09 18:40 UTC), the same constant time-window used directly.
+	StellarGenesisUnix = 1436467200
+	// ApproxLedgerCloseSeconds is the nominal ledger close cadence used
+	// only as a last-resort estimate.
+	ApproxLedgerCloseSeconds = 5
+
+	defaultSanityBound   = time.Hour
+	defaultBackfillQueue = 256
+	defaultEvictRows     = 1_000_000
+	evictEvery           = 1000
+)
+
+// Approx estimates a ledger's close time using the fixed 5-second model.
+func Approx(ledger uint32) int64 {
+	return StellarGenesisUnix + int64(ledger)*ApproxLedgerCloseSeconds
+}
+
+// Options holds the flag values AddFlags binds.
+type Options struct {
+	CachePath string
+	Source    string // horizon|core|meta
+	SourceURL string
+}
+
+// AddFlags registers --ledgertime-cache, --ledgertime-source, and
+// --ledgertime-source-url on cmd and returns the Options they're bound to.
+func AddFlags(cmd *cobra.Command) *Options {
+	opts := &Options{}
+	cmd.Flags().StringVar(&opts.CachePath, "ledgertime-cache", "",
+		"SQLite cache path for resolved ledger close times (empty disables the cache and backfill)")
+	cmd.Flags().StringVar(&opts.Source, "ledgertime-source", "meta",
+		"Ledger close-time backfill source: horizon|core|meta (meta never hits the network, relying only on event close times already seen)")
+	cmd.Flags().StringVar(&opts.SourceURL, "ledgertime-source-url", "",
+		"Base URL for --ledgertime-source=horizon|core")
+	return opts
+}
+
+// Resolver resolves an event's close time, preferring meta.closeTime,
+// falling back to the Store cache, and falling back further to Approx
+// when neither is available, opportunistically backfilling the cache in
+// the background from Source.
+type Resolver struct {
+	store       *Store
+	source      Source
+	sanityBound time.Duration
+
+	// OnError receives errors from the background backfill goroutine; it
+	// may be nil to silently drop them (Resolve itself never fails: it
+	// always has the approximation to fall back on).
+	OnError func(error)
+
+	backfill chan uint32
+	stop     chan struct{}
+	inflight sync.Map // ledger uint32 -> struct{}
+
+	puts int64
+}
+
+// New builds a Resolver from opts. A --ledgertime-source of "meta" (or
+// empty) disables network backfill: the cache is only ever populated from
+// closeTime values already seen in event meta.
+func New(opts *Options) (*Resolver, error) {
+	r := &Resolver{sanityBound: defaultSanityBound}
+
+	if opts.CachePath != "" {
+		store, err := NewStore(opts.CachePath)
+		if err != nil {
+			return nil, err
+		}
+		r.store = store
+	}
+
+	switch opts.Source {
+	case "horizon":
+		if opts.SourceURL == "" {
+			return nil, fmt.Errorf("--ledgertime-source=horizon requires --ledgertime-source-url")
+		}
+		r.source = NewHorizonSource(opts.SourceURL)
+	case "core":
+		if opts.SourceURL == "" {
+			return nil, fmt.Errorf("--ledgertime-source=core requires --ledgertime-source-url")
+		}
+		r.source = NewCoreSource(opts.SourceURL)
+	case "meta", "":
+		// No network backfill.
+	default:
+		return nil, fmt.Errorf("unknown --ledgertime-source %q", opts.Source)
+	}
+
+	if r.store != nil && r.source != nil {
+		r.backfill = make(chan uint32, defaultBackfillQueue)
+		r.stop = make(chan struct{})
+		go r.runBackfill()
+	}
+
+	return r, nil
+}
+
+// Resolve returns event's close time and true, or false if event has no
+// ledger sequence to resolve one from (callers should drop the event in
+// that case, matching the previous filterByTimeWindow behavior).
+func (r *Resolver) Resolve(event map[string]interface{}) (closeTime int64, ok bool) {
+	meta, ok := event["meta"].(map[string]interface{})
+	if !ok {
+		return 0, false
+	}
+	seqF, ok := meta["ledgerSequence"].(float64)
+	if !ok {
+		return 0, false
+	}
+	ledger := uint32(seqF)
+	approx := Approx(ledger)
+
+	if ct, found := closeTimeFromMeta(meta); found {
+		if r.sane(ct, approx) {
+			r.cache(ledger, ct)
+			return ct, true
+		}
+	}
+
+	if r.store != nil {
+		if ct, found, err := r.store.Get(ledger); err == nil && found {
+			if r.sane(ct, approx) {
+				return ct, true
+			}
+			// Corrupt cache row: evict it and fall through to approx.
+			r.store.Delete(ledger)
+		}
+	}
+
+	r.queueBackfill(ledger)
+	return approx, true
+}
+
+// sane reports whether ct is within sanityBound of approx, used both to
+// accept a meta/cache value and to detect a corrupt cache row.
+func (r *Resolver) sane(ct, approx int64) bool {
+	diff := ct - approx
+	if diff < 0 {
+		diff = -diff
+	}
+	return time.Duration(diff)*time.Second < r.sanityBound
+}
+
+func (r *Resolver) cache(ledger uint32, closeTime int64) {
+	if r.store == nil {
+		return
+	}
+	if err := r.store.Put(ledger, closeTime); err != nil {
+		r.reportError(err)
+		return
+	}
+	r.puts++
+	if r.puts%evictEvery == 0 {
+		if err := r.store.Evict(defaultEvictRows); err != nil {
+			r.reportError(err)
+		}
+	}
+}
+
+// queueBackfill enqueues ledger for the background fetcher, deduplicating
+// against ledgers already in flight so a burst of events for the same
+// (not-yet-cached) ledger doesn't queue it hundreds of times.
+func (r *Resolver) queueBackfill(ledger uint32) {
+	if r.backfill == nil {
+		return
+	}
+	if _, already := r.inflight.LoadOrStore(ledger, struct{}{}); already {
+		return
+	}
+	select {
+	case r.backfill <- ledger:
+	default:
+		// Queue full: drop it, the approximation already covers this
+		// event and a later ledger's backfill will catch up eventually.
+		r.inflight.Delete(ledger)
+	}
+}
+
+func (r *Resolver) runBackfill() {
+	for {
+		select {
+		case <-r.stop:
+			return
+		case ledger := <-r.backfill:
+			ct, err := r.source.Fetch(ledger)
+			r.inflight.Delete(ledger)
+			if err != nil {
+				r.reportError(fmt.Errorf("ledgertime backfill for ledger %d: %w", ledger, err))
+				continue
+			}
+			if !r.sane(ct, Approx(ledger)) {
+				r.reportError(fmt.Errorf("ledgertime backfill for ledger %d returned %d, outside sanity bound of approximation", ledger, ct))
+				continue
+			}
+			r.cache(ledger, ct)
+		}
+	}
+}
+
+func (r *Resolver) reportError(err error) {
+	if r.OnError != nil {
+		r.OnError(err)
+	}
+}
+
+// Close stops the background backfill goroutine (if running) and closes
+// the cache.
+func (r *Resolver) Close() error {
+	if r.stop != nil {
+		close(r.stop)
+	}
+	if r.store != nil {
+		return r.store.Close()
+	}
+	return nil
+}
+
+// closeTimeFromMeta extracts meta.closeTime, which protojson may render as
+// either a JSON number or a numeric string (int64 fields are stringified
+// by protojson).
+func closeTimeFromMeta(meta map[string]interface{}) (int64, bool) {
+	switch v := meta["closeTime"].(type) {
+	case float64:
+		return int64(v), true
+	case string:
+		ct, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		return ct, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,92 @@
+package ledgertime
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Source fetches the close time for a single ledger sequence from an
+// external service, for the background backfill goroutine to populate the
+// cache with.
+type Source interface {
+	Fetch(ledger uint32) (closeTime int64, err error)
+}
+
+// HorizonSource fetches ledger close times from a Horizon instance's
+// /ledgers/{sequence} endpoint.
+type HorizonSource struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+// NewHorizonSource creates a HorizonSource against baseURL (e.g.
+// "https://horizon.stellar.org").
+func NewHorizonSource(baseURL string) *HorizonSource {
+	return &HorizonSource{BaseURL: baseURL, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (h *HorizonSource) Fetch(ledger uint32) (int64, error) {
+	url := fmt.Sprintf("%s/ledgers/%d", h.BaseURL, ledger)
+	resp, err := h.Client.Get(url)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch ledger %d from horizon: %w", ledger, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("horizon returned %s for ledger %d", resp.Status, ledger)
+	}
+
+	var body struct {
+		ClosedAt time.Time `json:"closed_at"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, fmt.Errorf("failed to decode horizon ledger %d: %w", ledger, err)
+	}
+	return body.ClosedAt.Unix(), nil
+}
+
+// CoreSource fetches ledger close times from a stellar-core HTTP admin
+// endpoint. Core's captive-core JSON differs from Horizon's: close time is
+// a Unix seconds integer ("closeTime") rather than an RFC3339 string.
+type CoreSource struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+// NewCoreSource creates a CoreSource against baseURL (e.g.
+// "http://localhost:11626").
+func NewCoreSource(baseURL string) *CoreSource {
+	return &CoreSource{BaseURL: baseURL, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (c *CoreSource) Fetch(ledger uint32) (int64, error) {
+	url := fmt.Sprintf("%s/ledgers?cursor=%d&limit=1", c.BaseURL, ledger)
+	resp, err := c.Client.Get(url)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch ledger %d from core: %w", ledger, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("core returned %s for ledger %d", resp.Status, ledger)
+	}
+
+	var body struct {
+		Ledgers []struct {
+			Sequence  uint32 `json:"sequence"`
+			CloseTime int64  `json:"closeTime"`
+		} `json:"ledgers"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, fmt.Errorf("failed to decode core ledger %d: %w", ledger, err)
+	}
+	for _, l := range body.Ledgers {
+		if l.Sequence == ledger {
+			return l.CloseTime, nil
+		}
+	}
+	return 0, fmt.Errorf("ledger %d not found in core response", ledger)
+}
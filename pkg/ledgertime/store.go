@@ -0,0 +1,102 @@
+package ledgertime
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "modernc.org/sqlite"
+)
+
+// Store is a local SQLite cache of ledger_sequence -> close_time_unix,
+// populated from Source fetches or from closeTime already present in an
+// event's meta, so repeated lookups for the same ledger range don't need a
+// network round trip.
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore opens (creating if needed) a SQLite database at path and
+// ensures the ledger_times table exists.
+func NewStore(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite ledgertime cache %s: %w", path, err)
+	}
+
+	const schema = `
+CREATE TABLE IF NOT EXISTS ledger_times (
+	ledger_sequence INTEGER PRIMARY KEY,
+	close_time_unix INTEGER NOT NULL
+)`
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create ledger_times table: %w", err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Get returns the cached close time for ledger, or ok=false if it isn't
+// cached yet.
+func (s *Store) Get(ledger uint32) (closeTime int64, ok bool, err error) {
+	err = s.db.QueryRow(
+		`SELECT close_time_unix FROM ledger_times WHERE ledger_sequence = ?`, ledger,
+	).Scan(&closeTime)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read ledger time for %d: %w", ledger, err)
+	}
+	return closeTime, true, nil
+}
+
+// Put caches closeTime for ledger, overwriting any previous value (a
+// corrected backfill should win over a stale one).
+func (s *Store) Put(ledger uint32, closeTime int64) error {
+	_, err := s.db.Exec(
+		`INSERT INTO ledger_times (ledger_sequence, close_time_unix) VALUES (?, ?)
+		 ON CONFLICT (ledger_sequence) DO UPDATE SET close_time_unix = excluded.close_time_unix`,
+		ledger, closeTime,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to cache ledger time for %d: %w", ledger, err)
+	}
+	return nil
+}
+
+// Delete evicts a single row, used when a sanity check flags it as
+// corrupt.
+func (s *Store) Delete(ledger uint32) error {
+	_, err := s.db.Exec(`DELETE FROM ledger_times WHERE ledger_sequence = ?`, ledger)
+	if err != nil {
+		return fmt.Errorf("failed to evict ledger time for %d: %w", ledger, err)
+	}
+	return nil
+}
+
+// Evict enforces maxRows by deleting the lowest ledger_sequence rows
+// first: since lookups track a processor's forward progress through
+// ledger history, the oldest (lowest) cached sequences are the least
+// likely to be queried again.
+func (s *Store) Evict(maxRows int64) error {
+	if maxRows <= 0 {
+		return nil
+	}
+	_, err := s.db.Exec(
+		`DELETE FROM ledger_times WHERE ledger_sequence IN (
+			SELECT ledger_sequence FROM ledger_times
+			ORDER BY ledger_sequence ASC
+			LIMIT MAX(0, (SELECT COUNT(*) FROM ledger_times) - ?)
+		)`, maxRows,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to evict ledger_times down to %d rows: %w", maxRows, err)
+	}
+	return nil
+}
+
+// Close releases the underlying database handle.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,212 @@
+// Package metrics provides typed Prometheus counters/histograms and an
+// admin HTTP listener (--metrics-addr) that processor binaries can opt into
+// to expose /metrics, /healthz, /readyz, and /debug/pprof, turning them from
+// opaque Unix filters into services that can be scraped and alerted on.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry bundles the common metrics every processor wants, labeled by the
+// processor's own name so one Prometheus target can scrape several
+// binaries.
+type Registry struct {
+	reg *prometheus.Registry
+
+	EventsIn        prometheus.Counter
+	EventsOut       prometheus.Counter
+	FilterDrops     prometheus.Counter
+	TransformErrors prometheus.Counter
+
+	SinkPublishLatency *prometheus.HistogramVec // labels: status
+	NATSReconnects     prometheus.Counter
+	BatchSize          prometheus.Histogram
+
+	LastLedgerProcessed prometheus.Gauge
+}
+
+// NewRegistry creates a Registry with all series pre-registered under a
+// "nebu_" namespace and a "processor" constant label.
+func NewRegistry(processorName string) *Registry {
+	reg := prometheus.NewRegistry()
+	factory := promauto.With(reg)
+	constLabels := prometheus.Labels{"processor": processorName}
+
+	r := &Registry{reg: reg}
+
+	r.EventsIn = factory.NewCounter(prometheus.CounterOpts{
+		Namespace:   "nebu",
+		Name:        "events_in_total",
+		Help:        "Events received by this processor.",
+		ConstLabels: constLabels,
+	})
+	r.EventsOut = factory.NewCounter(prometheus.CounterOpts{
+		Namespace:   "nebu",
+		Name:        "events_out_total",
+		Help:        "Events emitted by this processor.",
+		ConstLabels: constLabels,
+	})
+	r.FilterDrops = factory.NewCounter(prometheus.CounterOpts{
+		Namespace:   "nebu",
+		Name:        "filter_drops_total",
+		Help:        "Events dropped by a filter stage.",
+		ConstLabels: constLabels,
+	})
+	r.TransformErrors = factory.NewCounter(prometheus.CounterOpts{
+		Namespace:   "nebu",
+		Name:        "transform_errors_total",
+		Help:        "Errors returned by a transform stage.",
+		ConstLabels: constLabels,
+	})
+	r.SinkPublishLatency = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace:   "nebu",
+		Name:        "sink_publish_latency_seconds",
+		Help:        "Time to publish/write a single event or batch to the sink destination.",
+		ConstLabels: constLabels,
+		Buckets:     prometheus.DefBuckets,
+	}, []string{"status"})
+	r.NATSReconnects = factory.NewCounter(prometheus.CounterOpts{
+		Namespace:   "nebu",
+		Name:        "nats_reconnects_total",
+		Help:        "NATS connection reconnect events observed by this processor.",
+		ConstLabels: constLabels,
+	})
+	r.BatchSize = factory.NewHistogram(prometheus.HistogramOpts{
+		Namespace:   "nebu",
+		Name:        "batch_size",
+		Help:        "Number of events flushed per batch.",
+		ConstLabels: constLabels,
+		Buckets:     prometheus.ExponentialBuckets(1, 2, 12),
+	})
+	r.LastLedgerProcessed = factory.NewGauge(prometheus.GaugeOpts{
+		Namespace:   "nebu",
+		Name:        "last_ledger_processed",
+		Help:        "Sequence number of the last ledger this processor finished handling.",
+		ConstLabels: constLabels,
+	})
+
+	return r
+}
+
+// NATSPublishCounter creates and registers a
+// nats_publish_total{subject,status} counter vec on demand rather than in
+// NewRegistry, since only binaries that publish to NATS need it. The
+// result is not cached: a second call on the same Registry registers the
+// same collector again and panics.
+func (r *Registry) NATSPublishCounter() *prometheus.CounterVec {
+	return promauto.With(r.reg).NewCounterVec(prometheus.CounterOpts{
+		Namespace: "nebu",
+		Name:      "nats_publish_total",
+		Help:      "NATS publishes by subject and outcome.",
+	}, []string{"subject", "status"})
+}
+
+// ListCounters creates and registers the list_matched_total and
+// list_rejected_total counters used by filter.List on demand, since not
+// every processor wires one up. The result is not cached: a second call
+// on the same Registry panics on duplicate registration.
+func (r *Registry) ListCounters() (matched, rejected prometheus.Counter) {
+	factory := promauto.With(r.reg)
+	matched = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: "nebu",
+		Name:      "list_matched_total",
+		Help:      "Events kept by a filter.List allow/deny check.",
+	})
+	rejected = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: "nebu",
+		Name:      "list_rejected_total",
+		Help:      "Events dropped by a filter.List allow/deny check.",
+	})
+	return matched, rejected
+}
+
+// DLQCounter creates and registers (without caching: call it once per
+// Registry) the events_dlq_total{reason}
+// counter vec an origin's dead-letter queue increments every time it
+// quarantines an event, so operators can alert on sustained schema drift
+// from an upstream SDK (new event variants a convertEvent switch doesn't
+// handle yet) instead of discovering it only by noticing missing events.
+func (r *Registry) DLQCounter() *prometheus.CounterVec {
+	return promauto.With(r.reg).NewCounterVec(prometheus.CounterOpts{
+		Namespace: "nebu",
+		Name:      "events_dlq_total",
+		Help:      "Events quarantined to a dead-letter queue instead of being emitted, by reason.",
+	}, []string{"reason"})
+}
+
+// HTTPStreamCounters registers the token-transfer HTTP server's counters.
+func (r *Registry) HTTPStreamCounters() (eventsTotal *prometheus.CounterVec, activeConnections prometheus.Gauge) {
+	eventsTotal = promauto.With(r.reg).NewCounterVec(prometheus.CounterOpts{
+		Namespace: "nebu",
+		Name:      "http_stream_events_total",
+		Help:      "Events streamed to HTTP clients, by event type.",
+	}, []string{"type"})
+	activeConnections = promauto.With(r.reg).NewGauge(prometheus.GaugeOpts{
+		Namespace: "nebu",
+		Name:      "stream_active_connections",
+		Help:      "Number of currently open /events streaming connections.",
+	})
+	return eventsTotal, activeConnections
+}
+
+// Handler returns the plain /metrics HTTP handler for this registry, for
+// callers (like token_transfer.Server) that already own a mux and don't
+// need the full AdminServer.
+func (r *Registry) Handler() http.Handler {
+	return promhttp.HandlerFor(r.reg, promhttp.HandlerOpts{})
+}
+
+// AdminServer exposes /metrics, /healthz, /readyz, and /debug/pprof on a
+// dedicated mux bound to --metrics-addr.
+type AdminServer struct {
+	srv   *http.Server
+	ready func() bool
+}
+
+// NewAdminServer builds the admin HTTP server. ready reports readiness for
+// /readyz; pass nil to always report ready once the listener is up.
+func (r *Registry) NewAdminServer(addr string, ready func() bool) *AdminServer {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(r.reg, promhttp.HandlerOpts{}))
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, req *http.Request) {
+		if ready != nil && !ready() {
+			http.Error(w, "not ready", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ready"))
+	})
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	return &AdminServer{srv: &http.Server{Addr: addr, Handler: mux}, ready: ready}
+}
+
+// Start runs the admin server in the background. Errors other than a clean
+// shutdown are sent to errCh.
+func (a *AdminServer) Start(errCh chan<- error) {
+	go func() {
+		if err := a.srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errCh <- err
+		}
+	}()
+}
+
+// Shutdown gracefully stops the admin server.
+func (a *AdminServer) Shutdown(ctx context.Context) error {
+	return a.srv.Shutdown(ctx)
+}
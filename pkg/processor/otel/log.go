@@ -0,0 +1,46 @@
+package otel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Fields is the set of extra fields a LogEvent call attaches to its
+// structured log line, beyond the stable schema (event, ts, trace_id,
+// span_id) LogEvent always includes. Use the field names the request
+// schema fixes — ledger_sequence, tx_hash, op_index, event_type,
+// asset_code, duration_ms — so every origin/transform/sink's log lines
+// are greppable/joinable on the same keys regardless of which stage wrote
+// them.
+type Fields map[string]interface{}
+
+// LogEvent writes one JSON line to stderr (never stdout — stdout carries
+// the pipeline's NDJSON event stream and a log line there would corrupt
+// it) recording a past-tense event name (e.g. "ledger.processed",
+// "event.emitted", "event.filtered", "event.dropped"), the current time,
+// fields, and — if ctx carries an active span — that span's trace_id and
+// span_id, so every log line can be correlated back to the trace covering
+// the same pipeline invocation.
+func LogEvent(ctx context.Context, event string, fields Fields) {
+	line := make(map[string]interface{}, len(fields)+4)
+	for k, v := range fields {
+		line[k] = v
+	}
+	line["event"] = event
+	line["ts"] = time.Now().UTC().Format(time.RFC3339Nano)
+
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		line["trace_id"] = sc.TraceID().String()
+		line["span_id"] = sc.SpanID().String()
+	}
+
+	enc := json.NewEncoder(os.Stderr)
+	if err := enc.Encode(line); err != nil {
+		fmt.Fprintf(os.Stderr, "otel: failed to encode log event %q: %v\n", event, err)
+	}
+}
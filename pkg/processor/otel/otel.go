@@ -0,0 +1,77 @@
+// Package otel is a cross-cutting observability layer for origin, transform,
+// and sink binaries: OpenTelemetry spans around the stages an operator
+// actually needs to reconstruct ("ledger processed", "event emitted",
+// "event filtered"), plus structured JSON log lines carrying the same
+// trace/span ids, so a whole pipeline invocation — origin through however
+// many transforms to the sink — can be stitched back together from logs
+// alone even without a trace backend.
+//
+// It's zero-cost by default: Init only installs a real exporter when
+// OTEL_EXPORTER_OTLP_ENDPOINT is set, otherwise it installs an explicit
+// no-op TracerProvider, so Tracer(...).Start(...) calls are as cheap as the
+// OpenTelemetry API itself guarantees for a disabled provider.
+package otel
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	realotel "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// Init installs the TracerProvider and text-map propagator this package's
+// Tracer and trace-context helpers use for the remainder of the process.
+// When OTEL_EXPORTER_OTLP_ENDPOINT is unset it installs nooptracer — a
+// provider whose spans do no work and export nowhere — so every other
+// function in this package behaves identically whether or not an operator
+// has ever heard of OpenTelemetry. The returned shutdown func flushes and
+// closes the exporter (a no-op when nooptracer was installed) and should be
+// deferred by the caller's main.
+func Init(serviceName string) (shutdown func(context.Context) error, err error) {
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	realotel.SetTextMapPropagator(propagation.TraceContext{})
+
+	if endpoint == "" {
+		realotel.SetTracerProvider(nooptracer())
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracegrpc.New(context.Background(), otlptracegrpc.WithEndpoint(endpoint), otlptracegrpc.WithInsecure())
+	if err != nil {
+		return nil, fmt.Errorf("otel: failed to create OTLP exporter for %s: %w", endpoint, err)
+	}
+
+	res, err := resource.New(context.Background(), resource.WithAttributes(semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, fmt.Errorf("otel: failed to build resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	realotel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// nooptracer returns the explicit zero-cost default TracerProvider this
+// package installs when no OTLP endpoint is configured.
+func nooptracer() trace.TracerProvider {
+	return noop.NewTracerProvider()
+}
+
+// Tracer returns a named tracer from whatever provider Init installed (or
+// the OpenTelemetry API's own global default, if Init was never called —
+// itself a no-op until a real SDK registers one).
+func Tracer(name string) trace.Tracer {
+	return realotel.Tracer(name)
+}
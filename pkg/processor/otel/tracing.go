@@ -0,0 +1,124 @@
+package otel
+
+import (
+	"context"
+	"time"
+
+	"github.com/stellar/go-stellar-sdk/xdr"
+	"github.com/withObsrvr/nebu/pkg/processor"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceProcessLedger starts a root span named "ledger.process" for ledger,
+// runs fn (the origin's real ProcessLedger body) inside it, records the
+// outcome, and emits a "ledger.processed" structured log line — the
+// integration point an origin's ProcessLedger wraps its body in:
+//
+//	func (o *Origin) ProcessLedger(ctx context.Context, ledger xdr.LedgerCloseMeta) error {
+//		return otel.TraceProcessLedger(ctx, o.tracer, ledger, func(ctx context.Context) error {
+//			... existing body, using ctx ...
+//		})
+//	}
+func TraceProcessLedger(ctx context.Context, tracer trace.Tracer, ledger xdr.LedgerCloseMeta, fn func(ctx context.Context) error) error {
+	sequence := ledger.LedgerSequence()
+	start := time.Now()
+
+	ctx, span := tracer.Start(ctx, "ledger.process", trace.WithAttributes(
+		attribute.Int64("ledger_sequence", int64(sequence)),
+	))
+	defer span.End()
+
+	err := fn(ctx)
+	duration := time.Since(start)
+
+	fields := Fields{
+		"ledger_sequence": sequence,
+		"duration_ms":     duration.Milliseconds(),
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		fields["error"] = err.Error()
+	}
+	LogEvent(ctx, "ledger.processed", fields)
+
+	return err
+}
+
+// EmitTraced starts a child span around handing event to emitter, then logs
+// "event.emitted" with the span's trace/span ids — the integration point
+// for Emitter.Emit calls inside ProcessLedger:
+//
+//	otel.EmitTraced(ctx, o.tracer, o.emitter, "transfer", pbEvent)
+func EmitTraced[T any](ctx context.Context, tracer trace.Tracer, emitter *processor.Emitter[T], eventType string, event T) {
+	ctx, span := tracer.Start(ctx, "event.emit", trace.WithAttributes(
+		attribute.String("event_type", eventType),
+	))
+	defer span.End()
+
+	emitter.Emit(event)
+	LogEvent(ctx, "event.emitted", Fields{"event_type": eventType})
+}
+
+// metaCarrier adapts a TokenTransferEvent-style EventMeta's trace context
+// field — always the JSON map representation (event["meta"]
+// ["traceContext"]), since this repo's proto types are generated
+// elsewhere and this tree has no .proto source to add a real TraceContext
+// field to — into a propagation.TextMapCarrier so the standard
+// propagation.TraceContext{} (W3C traceparent) can inject/extract through
+// it.
+type metaCarrier map[string]interface{}
+
+func (c metaCarrier) Get(key string) string {
+	v, _ := c[key].(string)
+	return v
+}
+
+func (c metaCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+func (c metaCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// InjectTraceContext writes ctx's active span's W3C traceparent into
+// event["meta"]["traceContext"], creating event["meta"] if absent, so a
+// downstream transform can continue the same trace after the event
+// round-trips through NDJSON on a pipe.
+func InjectTraceContext(ctx context.Context, event map[string]interface{}) {
+	meta, ok := event["meta"].(map[string]interface{})
+	if !ok {
+		meta = make(map[string]interface{})
+		event["meta"] = meta
+	}
+	tc := make(map[string]interface{})
+	propagation.TraceContext{}.Inject(ctx, metaCarrier(tc))
+	if len(tc) > 0 {
+		meta["traceContext"] = tc
+	}
+}
+
+// ExtractTraceContext reads a traceparent previously written by
+// InjectTraceContext out of event["meta"]["traceContext"] and returns a
+// context carrying it as the remote parent span, so a transform can start
+// a child span that appears under the origin's trace instead of starting
+// a disconnected one.
+func ExtractTraceContext(ctx context.Context, event map[string]interface{}) context.Context {
+	meta, ok := event["meta"].(map[string]interface{})
+	if !ok {
+		return ctx
+	}
+	tc, ok := meta["traceContext"].(map[string]interface{})
+	if !ok {
+		return ctx
+	}
+	return propagation.TraceContext{}.Extract(ctx, metaCarrier(tc))
+}
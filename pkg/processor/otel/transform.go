@@ -0,0 +1,44 @@
+package otel
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/codes"
+)
+
+// TransformFunc is the function shape cli.RunTransformCLI takes: read one
+// event, return the (possibly modified) event to keep and forward, nil to
+// drop it, or an error to abort.
+type TransformFunc func(event map[string]interface{}) (map[string]interface{}, error)
+
+// WrapTransformFunc instruments fn for cli.RunTransformCLI: it extracts
+// the upstream trace context from event's meta (see ExtractTraceContext),
+// starts a child span named name+".transform", calls fn, and logs
+// "event.filtered" (fn returned nil, nil) or "event.dropped" (fn returned
+// an error). A kept event is not logged; the continued trace context is
+// injected into it so the next stage can extract it. name should be the processor's own
+// name (e.g. "amount-filter"), matching pkg/metrics.NewRegistry's
+// processorName convention.
+func WrapTransformFunc(name string, fn TransformFunc) TransformFunc {
+	tracer := Tracer(name)
+	return func(event map[string]interface{}) (map[string]interface{}, error) {
+		ctx := ExtractTraceContext(context.Background(), event)
+		ctx, span := tracer.Start(ctx, name+".transform")
+		defer span.End()
+
+		out, err := fn(event)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			LogEvent(ctx, "event.dropped", Fields{"reason": err.Error()})
+			return nil, err
+		}
+		if out == nil {
+			LogEvent(ctx, "event.filtered", nil)
+			return nil, nil
+		}
+
+		InjectTraceContext(ctx, out)
+		return out, nil
+	}
+}
@@ -0,0 +1,207 @@
+// Package replay drives an origin processor over a historical ledger range
+// with a worker pool, independent of the single-goroutine, single-range
+// cli.RunOriginCLI / runtime.RunOrigin path used for live streaming. It
+// exists for indexer bootstraps and gap repair, where fetching ledgers one
+// at a time from source.LedgerSource is the bottleneck and a crash partway
+// through a multi-million-ledger backfill shouldn't mean starting over.
+package replay
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stellar/go-stellar-sdk/xdr"
+	"github.com/withObsrvr/nebu-processor-registry/pkg/checkpoint"
+	"github.com/withObsrvr/nebu/pkg/source"
+)
+
+// ProcessFunc extracts zero or more events of type T from one ledger. It
+// must be safe to call concurrently from multiple workers: Runner makes no
+// guarantee about which worker processes which ledger.
+type ProcessFunc[T any] func(ctx context.Context, ledger xdr.LedgerCloseMeta) ([]T, error)
+
+// Options configures a Runner.
+type Options struct {
+	StartLedger uint32
+	EndLedger   uint32
+
+	// Workers is the number of concurrent ledger fetches. Defaults to 4.
+	Workers int
+
+	// Store, ProcessorName, and ShardName checkpoint the last
+	// fully-emitted ledger, reusing pkg/checkpoint.Store so a replay run
+	// shares its DSN scheme (file://, sqlite://, redis://, nats://) with
+	// every other checkpointed processor; a Postgres- or BoltDB-backed
+	// Store can be added later as another checkpoint.Open scheme without
+	// changing Runner's contract. Store may be nil to disable
+	// checkpointing (every run starts from StartLedger).
+	Store         checkpoint.Store
+	ProcessorName string
+	ShardName     string // defaults to "replay" if empty
+}
+
+// Runner fans ledger fetches for [StartLedger, EndLedger] out across
+// Workers goroutines pulling from src, re-orders their results back into
+// strictly increasing ledger-sequence order before calling process, and
+// checkpoints after each ledger's events have all been emitted. On a
+// fresh Run call it resumes past whatever ledger was last checkpointed,
+// skipping ledgers already emitted by a prior run.
+type Runner[T any] struct {
+	src     source.LedgerSource
+	opts    Options
+	process ProcessFunc[T]
+}
+
+// NewRunner creates a Runner. process is called once per ledger in the
+// range, potentially concurrently across workers.
+func NewRunner[T any](src source.LedgerSource, opts Options, process ProcessFunc[T]) *Runner[T] {
+	if opts.Workers <= 0 {
+		opts.Workers = 4
+	}
+	if opts.ShardName == "" {
+		opts.ShardName = "replay"
+	}
+	return &Runner[T]{src: src, opts: opts, process: process}
+}
+
+// ledgerResult is one worker's outcome for a single ledger sequence.
+type ledgerResult[T any] struct {
+	seq    uint32
+	events []T
+	err    error
+}
+
+// Run starts the replay and returns a channel of in-order events plus an
+// error channel. Both channels are closed once the range completes, ctx is
+// canceled, or an unrecoverable fetch/process error occurs; a received
+// error always precedes channel closure.
+func (r *Runner[T]) Run(ctx context.Context) (<-chan T, <-chan error) {
+	out := make(chan T, 256)
+	errCh := make(chan error, 1)
+
+	start := r.opts.StartLedger
+	if r.opts.Store != nil {
+		if last, err := r.opts.Store.Load(r.opts.ProcessorName, r.opts.ShardName); err == nil && last+1 > start {
+			start = last + 1
+		}
+	}
+
+	go r.run(ctx, start, out, errCh)
+	return out, errCh
+}
+
+func (r *Runner[T]) run(ctx context.Context, start uint32, out chan<- T, errCh chan<- error) {
+	defer close(out)
+	defer close(errCh)
+
+	end := r.opts.EndLedger
+	if start > end {
+		return // checkpoint already covers the whole requested range
+	}
+
+	jobs := make(chan uint32)
+	results := make(chan ledgerResult[T], r.opts.Workers*2)
+
+	done := make(chan struct{})
+	for i := 0; i < r.opts.Workers; i++ {
+		go r.worker(ctx, jobs, results, done)
+	}
+
+	go func() {
+		defer close(jobs)
+		for seq := start; seq <= end; seq++ {
+			select {
+			case <-ctx.Done():
+				return
+			case jobs <- seq:
+			}
+		}
+	}()
+
+	go func() {
+		for i := 0; i < r.opts.Workers; i++ {
+			<-done
+		}
+		close(results)
+	}()
+
+	r.reorder(ctx, start, end, results, out, errCh)
+}
+
+func (r *Runner[T]) worker(ctx context.Context, jobs <-chan uint32, results chan<- ledgerResult[T], done chan<- struct{}) {
+	defer func() { done <- struct{}{} }()
+
+	for seq := range jobs {
+		ledger, err := r.src.GetLedger(ctx, seq)
+		var res ledgerResult[T]
+		if err != nil {
+			res = ledgerResult[T]{seq: seq, err: fmt.Errorf("fetch ledger %d: %w", seq, err)}
+		} else {
+			events, err := r.process(ctx, ledger)
+			if err != nil {
+				err = fmt.Errorf("process ledger %d: %w", seq, err)
+			}
+			res = ledgerResult[T]{seq: seq, events: events, err: err}
+		}
+
+		select {
+		case results <- res:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// reorder buffers out-of-order worker results until the next expected
+// ledger sequence arrives, then drains it and any subsequent
+// already-buffered ledgers in order, checkpointing after each.
+func (r *Runner[T]) reorder(ctx context.Context, start, end uint32, results <-chan ledgerResult[T], out chan<- T, errCh chan<- error) {
+	pending := make(map[uint32]ledgerResult[T])
+	next := start
+
+	for next <= end {
+		select {
+		case <-ctx.Done():
+			errCh <- ctx.Err()
+			return
+		case res, ok := <-results:
+			if !ok {
+				return // workers stopped early (ctx canceled mid-flight)
+			}
+			if res.err != nil {
+				errCh <- res.err
+				return
+			}
+			pending[res.seq] = res
+		}
+
+		for {
+			res, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+
+			for _, ev := range res.events {
+				select {
+				case <-ctx.Done():
+					errCh <- ctx.Err()
+					return
+				case out <- ev:
+				}
+			}
+
+			r.checkpoint(next)
+			next++
+		}
+	}
+}
+
+func (r *Runner[T]) checkpoint(ledger uint32) {
+	if r.opts.Store == nil {
+		return
+	}
+	// Best-effort: a failed save just means a restart redoes a bit more
+	// work, not data loss, same tradeoff pkg/checkpoint.Advancer makes.
+	_ = r.opts.Store.Save(r.opts.ProcessorName, r.opts.ShardName, ledger)
+}
@@ -0,0 +1,50 @@
+package routing
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the parsed form of a --route-file. Rules are evaluated in
+// order and the first matching rule wins.
+type Config struct {
+	Rules []RuleConfig `yaml:"rules"`
+	NATS  NATSConfig   `yaml:"nats"`
+}
+
+// RuleConfig is one routing rule: if When evaluates true for an event (or
+// is empty, matching everything), the event is sent to To.
+type RuleConfig struct {
+	When string `yaml:"when"`
+	To   string `yaml:"to"`
+}
+
+// NATSConfig holds the single shared NATS connection settings used by any
+// nats:// or nats+js:// destination in the route file.
+type NATSConfig struct {
+	URL   string `yaml:"url"`
+	Creds string `yaml:"creds"`
+}
+
+// LoadConfig reads and parses a --route-file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read route file %s: %w", path, err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse route file %s: %w", path, err)
+	}
+	if len(cfg.Rules) == 0 {
+		return nil, fmt.Errorf("route file %s defines no rules", path)
+	}
+	if cfg.NATS.URL == "" {
+		cfg.NATS.URL = "nats://localhost:4222"
+	}
+
+	return &cfg, nil
+}
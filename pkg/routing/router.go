@@ -0,0 +1,258 @@
+package routing
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/withObsrvr/nebu-processor-registry/pkg/filter"
+)
+
+// rule is a compiled RuleConfig: the `when` expression parsed once, ready
+// to evaluate against many events.
+type rule struct {
+	when *filter.Expr // nil means "matches everything"
+	to   string
+}
+
+// Router evaluates an event against its rules in order and dispatches it
+// to the first match's destination. Destinations (NATS connection, open
+// file handles, HTTP client) are created lazily and reused across events.
+type Router struct {
+	rules  []rule
+	strict bool
+
+	natsCfg NATSConfig
+	nc      *nats.Conn
+	js      nats.JetStreamContext
+
+	httpClient *http.Client
+
+	mu    sync.Mutex
+	files map[string]*bufio.Writer
+	fhs   map[string]*os.File
+
+	routed  *prometheus.CounterVec
+	dropped prometheus.Counter
+}
+
+// New compiles cfg's rules into a Router. strict controls whether a
+// missing template variable in a `to` destination is a hard error (like
+// nats-sink's --strict) or falls back to "_unknown".
+func New(cfg *Config, strict bool, routed *prometheus.CounterVec, dropped prometheus.Counter) (*Router, error) {
+	rules := make([]rule, 0, len(cfg.Rules))
+	for i, rc := range cfg.Rules {
+		r := rule{to: rc.To}
+		if strings.TrimSpace(rc.When) != "" {
+			expr, err := filter.CompileExpr(rc.When)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: %w", i, err)
+			}
+			r.when = expr
+		}
+		rules = append(rules, r)
+	}
+
+	return &Router{
+		rules:      rules,
+		strict:     strict,
+		natsCfg:    cfg.NATS,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		files:      make(map[string]*bufio.Writer),
+		fhs:        make(map[string]*os.File),
+		routed:     routed,
+		dropped:    dropped,
+	}, nil
+}
+
+// Route evaluates event against the router's rules in order and dispatches
+// it to the first match. An event matching no rule is counted as dropped,
+// not an error, since "no route for this event" is expected in a
+// multi-tenant routing table.
+func (r *Router) Route(event map[string]interface{}) error {
+	for i, rule := range r.rules {
+		if rule.when != nil {
+			matched, err := rule.when.Eval(event)
+			if err != nil {
+				return fmt.Errorf("rule %d: %w", i, err)
+			}
+			if !matched {
+				continue
+			}
+		}
+
+		dest, err := Resolve(rule.to, event, r.strict)
+		if err != nil {
+			return fmt.Errorf("rule %d: %w", i, err)
+		}
+
+		err = r.send(dest, event)
+		status := "ok"
+		if err != nil {
+			status = "error"
+		}
+		if r.routed != nil {
+			r.routed.WithLabelValues(dest, status).Inc()
+		}
+		return err
+	}
+
+	if r.dropped != nil {
+		r.dropped.Inc()
+	}
+	return nil
+}
+
+func (r *Router) send(dest string, event map[string]interface{}) error {
+	u, err := url.Parse(dest)
+	if err != nil {
+		return fmt.Errorf("invalid destination %q: %w", dest, err)
+	}
+
+	switch u.Scheme {
+	case "nats":
+		return r.sendNATS(u, event, false)
+	case "nats+js":
+		return r.sendNATS(u, event, true)
+	case "file":
+		return r.sendFile(u, event)
+	case "http", "https":
+		return r.sendHTTP(dest, event)
+	case "stdout":
+		return r.sendStdout(event)
+	default:
+		return fmt.Errorf("unsupported destination scheme %q", u.Scheme)
+	}
+}
+
+func (r *Router) connectNATS() error {
+	if r.nc != nil {
+		return nil
+	}
+
+	opts := []nats.Option{nats.Name("router")}
+	if r.natsCfg.Creds != "" {
+		opts = append(opts, nats.UserCredentials(r.natsCfg.Creds))
+	}
+
+	nc, err := nats.Connect(r.natsCfg.URL, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to connect to NATS at %s: %w", r.natsCfg.URL, err)
+	}
+	r.nc = nc
+
+	js, err := nc.JetStream()
+	if err != nil {
+		return fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+	r.js = js
+	return nil
+}
+
+func (r *Router) sendNATS(u *url.URL, event map[string]interface{}, jetstream bool) error {
+	if err := r.connectNATS(); err != nil {
+		return err
+	}
+
+	subject := u.Host + u.Path
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	if jetstream {
+		_, err = r.js.Publish(subject, data)
+	} else {
+		err = r.nc.Publish(subject, data)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", subject, err)
+	}
+	return nil
+}
+
+func (r *Router) sendFile(u *url.URL, event map[string]interface{}) error {
+	path := u.Path
+
+	r.mu.Lock()
+	w, ok := r.files[path]
+	if !ok {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			r.mu.Unlock()
+			return fmt.Errorf("failed to open %s: %w", path, err)
+		}
+		w = bufio.NewWriter(f)
+		r.files[path] = w
+		r.fhs[path] = f
+	}
+	r.mu.Unlock()
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write to %s: %w", path, err)
+	}
+	return w.Flush()
+}
+
+func (r *Router) sendHTTP(dest string, event map[string]interface{}) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	resp, err := r.httpClient.Post(dest, "application/x-ndjson", bytes.NewReader(append(data, '\n')))
+	if err != nil {
+		return fmt.Errorf("failed to POST to %s: %w", dest, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("POST to %s returned %s", dest, resp.Status)
+	}
+	return nil
+}
+
+func (r *Router) sendStdout(event map[string]interface{}) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	_, err = os.Stdout.Write(append(data, '\n'))
+	return err
+}
+
+// Close flushes and closes every open file destination and the NATS
+// connection, if any were used.
+func (r *Router) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for path, w := range r.files {
+		if err := w.Flush(); err != nil {
+			return fmt.Errorf("failed to flush %s: %w", path, err)
+		}
+	}
+	for _, f := range r.fhs {
+		f.Close()
+	}
+	if r.nc != nil {
+		r.nc.Flush()
+		r.nc.Close()
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// Package routing provides the subject-template resolution and rule-based
+// dispatch behind processors/router. The template logic started life
+// inside nats-sink's resolveSubject; it's extracted here so both nats-sink
+// and the general-purpose router can share it.
+package routing
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var templateVarRe = regexp.MustCompile(`\{([^}]+)\}`)
+
+// Resolve expands {key} / {nested.key} placeholders in template against
+// event's fields using dot-notation lookup. Resolved values have dots and
+// spaces replaced with underscores so they can't break a NATS subject
+// hierarchy or a file path. If strict is true, a missing placeholder is a
+// hard error; otherwise it resolves to "_unknown".
+func Resolve(template string, event map[string]interface{}, strict bool) (string, error) {
+	if !strings.Contains(template, "{") {
+		return template, nil
+	}
+
+	result := template
+	var missing error
+
+	for _, match := range templateVarRe.FindAllStringSubmatch(template, -1) {
+		placeholder := match[0]
+		path := match[1]
+
+		value, ok := lookup(event, path)
+		if !ok {
+			if strict {
+				missing = fmt.Errorf("template variable %q not found in event", path)
+				break
+			}
+			value = "_unknown"
+		}
+
+		result = strings.ReplaceAll(result, placeholder, sanitize(value))
+	}
+
+	if missing != nil {
+		return "", missing
+	}
+	return result, nil
+}
+
+// lookup resolves a dot-notation path ("transfer.assetCode") against a
+// nested map[string]interface{} event.
+func lookup(event map[string]interface{}, path string) (string, bool) {
+	parts := strings.Split(path, ".")
+
+	var current interface{} = event
+	for _, part := range parts {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		val, exists := m[part]
+		if !exists {
+			return "", false
+		}
+		current = val
+	}
+
+	return fmt.Sprint(current), true
+}
+
+func sanitize(s string) string {
+	s = strings.ReplaceAll(s, ".", "_")
+	s = strings.ReplaceAll(s, " ", "_")
+	return s
+}
+
+// FatalMissing mirrors nats-sink's original strict-mode behavior of
+// printing the error and exiting rather than returning it, for callers
+// that want drop-in compatibility.
+func FatalMissing(err error) {
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	os.Exit(1)
+}
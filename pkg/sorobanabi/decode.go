@@ -0,0 +1,202 @@
+// Package sorobanabi provides shared decoding primitives for turning
+// Soroban xdr.ScVal values into plain Go types — the runtime counterpart
+// to pkg/sorobangen's generated struct decoders.
+package sorobanabi
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/stellar/go-stellar-sdk/strkey"
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+// Bool decodes an ScvBool.
+func Bool(val xdr.ScVal) (bool, error) {
+	if val.Type != xdr.ScValTypeScvBool {
+		return false, unexpectedType(val, xdr.ScValTypeScvBool)
+	}
+	return val.MustB(), nil
+}
+
+// U32 decodes an ScvU32.
+func U32(val xdr.ScVal) (uint32, error) {
+	if val.Type != xdr.ScValTypeScvU32 {
+		return 0, unexpectedType(val, xdr.ScValTypeScvU32)
+	}
+	return uint32(val.MustU32()), nil
+}
+
+// I32 decodes an ScvI32.
+func I32(val xdr.ScVal) (int32, error) {
+	if val.Type != xdr.ScValTypeScvI32 {
+		return 0, unexpectedType(val, xdr.ScValTypeScvI32)
+	}
+	return int32(val.MustI32()), nil
+}
+
+// U64 decodes an ScvU64.
+func U64(val xdr.ScVal) (uint64, error) {
+	if val.Type != xdr.ScValTypeScvU64 {
+		return 0, unexpectedType(val, xdr.ScValTypeScvU64)
+	}
+	return uint64(val.MustU64()), nil
+}
+
+// I64 decodes an ScvI64.
+func I64(val xdr.ScVal) (int64, error) {
+	if val.Type != xdr.ScValTypeScvI64 {
+		return 0, unexpectedType(val, xdr.ScValTypeScvI64)
+	}
+	return int64(val.MustI64()), nil
+}
+
+// U128 decodes an ScvU128 into a *big.Int.
+func U128(val xdr.ScVal) (*big.Int, error) {
+	if val.Type != xdr.ScValTypeScvU128 {
+		return nil, unexpectedType(val, xdr.ScValTypeScvU128)
+	}
+	parts := val.MustU128()
+	hi := new(big.Int).SetUint64(uint64(parts.Hi))
+	lo := new(big.Int).SetUint64(uint64(parts.Lo))
+	return new(big.Int).Or(new(big.Int).Lsh(hi, 64), lo), nil
+}
+
+// I128 decodes an ScvI128 into a *big.Int, honoring the sign carried in
+// the high 64 bits.
+func I128(val xdr.ScVal) (*big.Int, error) {
+	if val.Type != xdr.ScValTypeScvI128 {
+		return nil, unexpectedType(val, xdr.ScValTypeScvI128)
+	}
+	parts := val.MustI128()
+	hi := big.NewInt(int64(parts.Hi))
+	lo := new(big.Int).SetUint64(uint64(parts.Lo))
+	return new(big.Int).Or(new(big.Int).Lsh(hi, 64), lo), nil
+}
+
+// String decodes an ScvString.
+func String(val xdr.ScVal) (string, error) {
+	if val.Type != xdr.ScValTypeScvString {
+		return "", unexpectedType(val, xdr.ScValTypeScvString)
+	}
+	return string(val.MustStr()), nil
+}
+
+// Symbol decodes an ScvSymbol.
+func Symbol(val xdr.ScVal) (string, error) {
+	if val.Type != xdr.ScValTypeScvSymbol {
+		return "", unexpectedType(val, xdr.ScValTypeScvSymbol)
+	}
+	return string(val.MustSym()), nil
+}
+
+// Bytes decodes an ScvBytes.
+func Bytes(val xdr.ScVal) ([]byte, error) {
+	if val.Type != xdr.ScValTypeScvBytes {
+		return nil, unexpectedType(val, xdr.ScValTypeScvBytes)
+	}
+	return val.MustBytes(), nil
+}
+
+// Address decodes an ScvAddress (account or contract) into its strkey
+// string form.
+func Address(val xdr.ScVal) (string, error) {
+	if val.Type != xdr.ScValTypeScvAddress {
+		return "", unexpectedType(val, xdr.ScValTypeScvAddress)
+	}
+
+	address := val.MustAddress()
+	switch address.Type {
+	case xdr.ScAddressTypeScAddressTypeAccount:
+		accountID := address.MustAccountId()
+		return accountID.Address(), nil
+	case xdr.ScAddressTypeScAddressTypeContract:
+		contractID := address.MustContractId()
+		return strkey.Encode(strkey.VersionByteContract, contractID[:])
+	default:
+		return "", fmt.Errorf("sorobanabi: unsupported address type %v", address.Type)
+	}
+}
+
+// Vec builds a decoder for ScvVec given an element decoder, inferring T
+// from elem so callers can write sorobanabi.Vec(sorobanabi.Address)
+// without an explicit type argument.
+func Vec[T any](elem func(xdr.ScVal) (T, error)) func(xdr.ScVal) ([]T, error) {
+	return func(val xdr.ScVal) ([]T, error) {
+		if val.Type != xdr.ScValTypeScvVec {
+			return nil, unexpectedType(val, xdr.ScValTypeScvVec)
+		}
+		vec := val.MustVec()
+		if vec == nil {
+			return nil, nil
+		}
+		result := make([]T, len(*vec))
+		for i, item := range *vec {
+			decoded, err := elem(item)
+			if err != nil {
+				return nil, fmt.Errorf("sorobanabi: vec element %d: %w", i, err)
+			}
+			result[i] = decoded
+		}
+		return result, nil
+	}
+}
+
+// Option builds a decoder for an optional value represented as ScvVoid
+// (absent) or the wrapped type (present), inferring T from elem.
+func Option[T any](elem func(xdr.ScVal) (T, error)) func(xdr.ScVal) (*T, error) {
+	return func(val xdr.ScVal) (*T, error) {
+		if val.Type == xdr.ScValTypeScvVoid {
+			return nil, nil
+		}
+		decoded, err := elem(val)
+		if err != nil {
+			return nil, err
+		}
+		return &decoded, nil
+	}
+}
+
+// Tuple decodes an ScvVec positionally, calling each decoder in decoders
+// against the element at its index. Used for generated tuple-struct
+// fields, where each field's Go type may differ from its neighbors'.
+func Tuple(val xdr.ScVal, decoders ...func(xdr.ScVal) error) error {
+	if val.Type != xdr.ScValTypeScvVec {
+		return unexpectedType(val, xdr.ScValTypeScvVec)
+	}
+	vec := val.MustVec()
+	if vec == nil || len(*vec) != len(decoders) {
+		return fmt.Errorf("sorobanabi: tuple expected %d elements, got %d", len(decoders), vecLen(vec))
+	}
+	for i, decode := range decoders {
+		if err := decode((*vec)[i]); err != nil {
+			return fmt.Errorf("sorobanabi: tuple element %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// MapKey extracts the string form of an ScvMap key or union-case
+// discriminant — expected to be an ScvSymbol or ScvString, the two key
+// forms Soroban uses to name a struct field or union case.
+func MapKey(val xdr.ScVal) string {
+	switch val.Type {
+	case xdr.ScValTypeScvSymbol:
+		return string(val.MustSym())
+	case xdr.ScValTypeScvString:
+		return string(val.MustStr())
+	default:
+		return ""
+	}
+}
+
+func vecLen(vec *xdr.ScVec) int {
+	if vec == nil {
+		return 0
+	}
+	return len(*vec)
+}
+
+func unexpectedType(val xdr.ScVal, want xdr.ScValType) error {
+	return fmt.Errorf("sorobanabi: expected %v, got %v", want, val.Type)
+}
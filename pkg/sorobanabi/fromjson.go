@@ -0,0 +1,163 @@
+package sorobanabi
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+)
+
+// The FromJSON decoders mirror the ScVal decoders above, but operate on
+// the generic interface{} a Go JSON decoder produces (from
+// contract_invocation.ConvertScValToJSON's output) rather than on a raw
+// xdr.ScVal. contract_invocation.Origin.Subscribe only exposes
+// invocation arguments in this already-JSON-encoded form, so generated
+// Filter<FunctionName> methods decode through here rather than through
+// the ScVal-based decoders, which require a raw xdr.ScVal a caller
+// doesn't have access to on that path.
+//
+// Caveat: U128FromJSON/I128FromJSON reconstruct their value from the
+// "hi"/"lo" components as encoded by json.Marshal, which represents them
+// as float64 — values outside float64's 53-bit exact integer range lose
+// precision on this path. Prefer the ScVal-based decoders when a raw
+// xdr.ScVal is available.
+
+func BoolFromJSON(j interface{}) (bool, error) {
+	v, ok := j.(bool)
+	if !ok {
+		return false, unexpectedJSONType(j, "bool")
+	}
+	return v, nil
+}
+
+func U32FromJSON(j interface{}) (uint32, error) {
+	f, err := jsonNumber(j)
+	if err != nil {
+		return 0, err
+	}
+	return uint32(f), nil
+}
+
+func I32FromJSON(j interface{}) (int32, error) {
+	f, err := jsonNumber(j)
+	if err != nil {
+		return 0, err
+	}
+	return int32(f), nil
+}
+
+func U64FromJSON(j interface{}) (uint64, error) {
+	f, err := jsonNumber(j)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(f), nil
+}
+
+func I64FromJSON(j interface{}) (int64, error) {
+	f, err := jsonNumber(j)
+	if err != nil {
+		return 0, err
+	}
+	return int64(f), nil
+}
+
+func U128FromJSON(j interface{}) (*big.Int, error) {
+	return int128FromJSON(j)
+}
+
+func I128FromJSON(j interface{}) (*big.Int, error) {
+	return int128FromJSON(j)
+}
+
+func int128FromJSON(j interface{}) (*big.Int, error) {
+	m, ok := j.(map[string]interface{})
+	if !ok {
+		return nil, unexpectedJSONType(j, "map[string]interface{} with hi/lo")
+	}
+	hi, err := jsonNumber(m["hi"])
+	if err != nil {
+		return nil, fmt.Errorf("sorobanabi: int128 hi: %w", err)
+	}
+	lo, err := jsonNumber(m["lo"])
+	if err != nil {
+		return nil, fmt.Errorf("sorobanabi: int128 lo: %w", err)
+	}
+	hiInt := big.NewInt(int64(hi))
+	loInt := new(big.Int).SetUint64(uint64(lo))
+	return new(big.Int).Or(new(big.Int).Lsh(hiInt, 64), loInt), nil
+}
+
+func StringFromJSON(j interface{}) (string, error) {
+	return stringFromJSON(j)
+}
+
+func SymbolFromJSON(j interface{}) (string, error) {
+	return stringFromJSON(j)
+}
+
+func AddressFromJSON(j interface{}) (string, error) {
+	return stringFromJSON(j)
+}
+
+func BytesFromJSON(j interface{}) ([]byte, error) {
+	s, err := stringFromJSON(j)
+	if err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(s)
+}
+
+func stringFromJSON(j interface{}) (string, error) {
+	s, ok := j.(string)
+	if !ok {
+		return "", unexpectedJSONType(j, "string")
+	}
+	return s, nil
+}
+
+func jsonNumber(j interface{}) (float64, error) {
+	f, ok := j.(float64)
+	if !ok {
+		return 0, unexpectedJSONType(j, "number")
+	}
+	return f, nil
+}
+
+// VecFromJSON builds a decoder for a JSON array given an element
+// decoder, inferring T from elem.
+func VecFromJSON[T any](elem func(interface{}) (T, error)) func(interface{}) ([]T, error) {
+	return func(j interface{}) ([]T, error) {
+		arr, ok := j.([]interface{})
+		if !ok {
+			return nil, unexpectedJSONType(j, "array")
+		}
+		result := make([]T, len(arr))
+		for i, item := range arr {
+			decoded, err := elem(item)
+			if err != nil {
+				return nil, fmt.Errorf("sorobanabi: vec element %d: %w", i, err)
+			}
+			result[i] = decoded
+		}
+		return result, nil
+	}
+}
+
+// OptionFromJSON builds a decoder for an optional JSON value (nil or the
+// wrapped type), inferring T from elem.
+func OptionFromJSON[T any](elem func(interface{}) (T, error)) func(interface{}) (*T, error) {
+	return func(j interface{}) (*T, error) {
+		if j == nil {
+			return nil, nil
+		}
+		decoded, err := elem(j)
+		if err != nil {
+			return nil, err
+		}
+		return &decoded, nil
+	}
+}
+
+func unexpectedJSONType(j interface{}, want string) error {
+	return fmt.Errorf("sorobanabi: expected %s, got %T", want, j)
+}
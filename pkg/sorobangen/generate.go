@@ -0,0 +1,81 @@
+package sorobangen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"text/template"
+)
+
+// Generate renders spec into a formatted Go source file.
+func Generate(spec *Spec) ([]byte, error) {
+	tmpl, err := template.New("sorobangen").Funcs(template.FuncMap{
+		"needsBigInt":     needsBigInt,
+		"needsXdr":        func(s *Spec) bool { return len(s.Structs) > 0 || len(s.Unions) > 0 },
+		"needsSorobanabi": needsSorobanabi,
+		"needsFmt":        needsFmt,
+		"needsJSON":       func(s *Spec) bool { return anyFunctionHasInputs(s) },
+		"add":             func(a, b int) int { return a + b },
+	}).Parse(sourceTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("sorobangen: parsing template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, spec); err != nil {
+		return nil, fmt.Errorf("sorobangen: executing template: %w", err)
+	}
+
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("sorobangen: generated source does not compile: %w\n%s", err, buf.String())
+	}
+
+	return formatted, nil
+}
+
+// needsBigInt reports whether spec references math/big anywhere, so the
+// template only imports it when actually used.
+func needsBigInt(spec *Spec) bool {
+	fields := make([]FieldDef, 0)
+	for _, s := range spec.Structs {
+		fields = append(fields, s.Fields...)
+	}
+	for _, u := range spec.Unions {
+		for _, c := range u.Cases {
+			fields = append(fields, c.Fields...)
+		}
+	}
+	for _, fn := range spec.Functions {
+		fields = append(fields, fn.Inputs...)
+		fields = append(fields, fn.Outputs...)
+	}
+	for _, f := range fields {
+		if f.GoType == "*big.Int" {
+			return true
+		}
+	}
+	return false
+}
+
+// needsSorobanabi reports whether spec generates any code that calls
+// into pkg/sorobanabi (struct/union decoders, or Filter methods that
+// decode function inputs).
+func needsSorobanabi(spec *Spec) bool {
+	return len(spec.Structs) > 0 || len(spec.Unions) > 0 || anyFunctionHasInputs(spec)
+}
+
+// needsFmt reports whether spec generates any code that calls fmt
+// (error wrapping in decoders).
+func needsFmt(spec *Spec) bool {
+	return len(spec.Structs) > 0 || len(spec.Unions) > 0 || anyFunctionHasInputs(spec)
+}
+
+func anyFunctionHasInputs(spec *Spec) bool {
+	for _, fn := range spec.Functions {
+		if len(fn.Inputs) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+// Package sorobangen turns a Soroban contract's SCSpecEntry metadata into
+// a typed Go origin processor: structs for its UDTs, decode expressions
+// built on pkg/sorobanabi, and one Filter<FunctionName> method per
+// contract function layered on contract_invocation.Origin.Subscribe.
+package sorobangen
+
+// Spec is the parsed, codegen-ready form of a contract's SCSpecEntry
+// stream.
+type Spec struct {
+	PackageName string
+	Structs     []StructDef
+	Unions      []UnionDef
+	Functions   []FunctionDef
+}
+
+// StructDef is a generated Go struct for an ScSpecUdtStructV0.
+type StructDef struct {
+	Name   string
+	Fields []FieldDef
+}
+
+// FieldDef is one struct field, function argument, or function return
+// value.
+type FieldDef struct {
+	// Name is the Go-exported identifier (e.g. "Amount").
+	Name string
+	// SpecName is the field/parameter's original spec name (e.g.
+	// "amount"), kept for Filter methods that need to look an argument
+	// up by its on-chain name.
+	SpecName string
+	// GoType is the field's Go type (e.g. "*big.Int", "string", "[]string").
+	GoType string
+	// Decode is a Go expression, in scope of a variable named "v" of
+	// type xdr.ScVal, that evaluates to (GoType, error).
+	Decode string
+	// DecodeJSON is a Go expression, in scope of a variable named "j" of
+	// type interface{} (as produced by contract_invocation's
+	// ConvertScValToJSON), that evaluates to (GoType, error). Used by
+	// generated Filter methods, which only have JSON-decoded arguments
+	// available, not a raw xdr.ScVal.
+	DecodeJSON string
+}
+
+// UnionCaseDef is one case of a generated union.
+type UnionCaseDef struct {
+	// Name is the case's Go-exported identifier (e.g. "Transfer").
+	Name string
+	// SpecName is the case's original spec name (e.g. "transfer").
+	SpecName string
+	// Fields is empty for a void case, or the tuple's positional fields
+	// for a tuple case.
+	Fields []FieldDef
+}
+
+// UnionDef is a generated Go representation of an ScSpecUdtUnionV0: a
+// struct with a Case discriminant plus one nil-able pointer field per
+// case, mirroring the xdr package's own discriminated-union convention.
+type UnionDef struct {
+	Name  string
+	Cases []UnionCaseDef
+}
+
+// FunctionDef is a generated Filter<FunctionName> method for an
+// ScSpecFunctionV0.
+type FunctionDef struct {
+	Name     string
+	SpecName string
+	Inputs   []FieldDef
+	Outputs  []FieldDef
+}
@@ -0,0 +1,230 @@
+package sorobangen
+
+import (
+	"fmt"
+
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+// ParseSpec walks a contract's SCSpecEntry stream (from
+// contractspec.ExtractSpecEntries or a JSON export) into a Spec ready
+// for template rendering.
+//
+// Only a bounded subset of SCSpecTypeDef is supported — Bool, U32, I32,
+// U64, I64, U128, I128, String, Symbol, Bytes, Address, Vec, Option, and
+// Udt references. Map, Result, Tuple, BytesN and anything nested inside
+// them are rejected rather than guessed at, since this repo has no
+// reference implementation to validate a fuller mapping against.
+func ParseSpec(entries []xdr.ScSpecEntry, pkgName string) (*Spec, error) {
+	spec := &Spec{PackageName: pkgName}
+
+	for _, entry := range entries {
+		switch entry.Kind {
+		case xdr.ScSpecEntryKindScSpecEntryFunctionV0:
+			fn, err := parseFunction(entry.MustFunctionV0())
+			if err != nil {
+				return nil, err
+			}
+			spec.Functions = append(spec.Functions, fn)
+
+		case xdr.ScSpecEntryKindScSpecEntryUdtStructV0:
+			s, err := parseStruct(entry.MustUdtStructV0())
+			if err != nil {
+				return nil, err
+			}
+			spec.Structs = append(spec.Structs, s)
+
+		case xdr.ScSpecEntryKindScSpecEntryUdtUnionV0:
+			u, err := parseUnion(entry.MustUdtUnionV0())
+			if err != nil {
+				return nil, err
+			}
+			spec.Unions = append(spec.Unions, u)
+
+		default:
+			// Event specs, enums and error-enums aren't part of this
+			// request's scope (structs/unions/functions only).
+			continue
+		}
+	}
+
+	return spec, nil
+}
+
+func parseFunction(fn xdr.ScSpecFunctionV0) (FunctionDef, error) {
+	def := FunctionDef{
+		Name:     exportName(string(fn.Name)),
+		SpecName: string(fn.Name),
+	}
+
+	for _, input := range fn.Inputs {
+		field, err := parseField(string(input.Name), input.Type)
+		if err != nil {
+			return FunctionDef{}, fmt.Errorf("function %s input %s: %w", fn.Name, input.Name, err)
+		}
+		def.Inputs = append(def.Inputs, field)
+	}
+
+	for i, output := range fn.Outputs {
+		field, err := parseField(fmt.Sprintf("Result%d", i), output)
+		if err != nil {
+			return FunctionDef{}, fmt.Errorf("function %s output %d: %w", fn.Name, i, err)
+		}
+		def.Outputs = append(def.Outputs, field)
+	}
+
+	return def, nil
+}
+
+func parseStruct(s xdr.ScSpecUdtStructV0) (StructDef, error) {
+	def := StructDef{Name: string(s.Name)}
+
+	for _, f := range s.Fields {
+		field, err := parseField(string(f.Name), f.Type)
+		if err != nil {
+			return StructDef{}, fmt.Errorf("struct %s field %s: %w", s.Name, f.Name, err)
+		}
+		def.Fields = append(def.Fields, field)
+	}
+
+	return def, nil
+}
+
+func parseUnion(u xdr.ScSpecUdtUnionV0) (UnionDef, error) {
+	def := UnionDef{Name: string(u.Name)}
+
+	for _, c := range u.Cases {
+		switch c.Kind {
+		case xdr.ScSpecUdtUnionCaseV0KindScSpecUdtUnionCaseVoidV0:
+			voidCase := c.MustVoidCase()
+			def.Cases = append(def.Cases, UnionCaseDef{
+				Name:     exportName(string(voidCase.Name)),
+				SpecName: string(voidCase.Name),
+			})
+
+		case xdr.ScSpecUdtUnionCaseV0KindScSpecUdtUnionCaseTupleV0:
+			tupleCase := c.MustTupleCase()
+			caseDef := UnionCaseDef{
+				Name:     exportName(string(tupleCase.Name)),
+				SpecName: string(tupleCase.Name),
+			}
+			for i, t := range tupleCase.Type {
+				field, err := parseField(fmt.Sprintf("Value%d", i), t)
+				if err != nil {
+					return UnionDef{}, fmt.Errorf("union %s case %s: %w", u.Name, tupleCase.Name, err)
+				}
+				caseDef.Fields = append(caseDef.Fields, field)
+			}
+			def.Cases = append(def.Cases, caseDef)
+
+		default:
+			return UnionDef{}, fmt.Errorf("union %s: unsupported case kind %v", u.Name, c.Kind)
+		}
+	}
+
+	return def, nil
+}
+
+func parseField(specName string, t xdr.ScSpecTypeDef) (FieldDef, error) {
+	goType, decode, decodeJSON, err := typeDefToGo(t)
+	if err != nil {
+		return FieldDef{}, err
+	}
+	return FieldDef{
+		Name:       exportName(specName),
+		SpecName:   specName,
+		GoType:     goType,
+		Decode:     decode,
+		DecodeJSON: decodeJSON,
+	}, nil
+}
+
+// typeDefToGo maps an ScSpecTypeDef to a Go type, a decode expression
+// (in scope of a variable "v" of type xdr.ScVal), and a decodeJSON
+// expression (in scope of a variable "j" of type interface{}) — both
+// evaluating to (GoType, error) — erroring on anything outside the
+// supported subset rather than guessing.
+func typeDefToGo(t xdr.ScSpecTypeDef) (goType, decode, decodeJSON string, err error) {
+	switch t.Type {
+	case xdr.ScSpecTypeScSpecTypeBool:
+		return "bool", "sorobanabi.Bool(v)", "sorobanabi.BoolFromJSON(j)", nil
+	case xdr.ScSpecTypeScSpecTypeU32:
+		return "uint32", "sorobanabi.U32(v)", "sorobanabi.U32FromJSON(j)", nil
+	case xdr.ScSpecTypeScSpecTypeI32:
+		return "int32", "sorobanabi.I32(v)", "sorobanabi.I32FromJSON(j)", nil
+	case xdr.ScSpecTypeScSpecTypeU64:
+		return "uint64", "sorobanabi.U64(v)", "sorobanabi.U64FromJSON(j)", nil
+	case xdr.ScSpecTypeScSpecTypeI64:
+		return "int64", "sorobanabi.I64(v)", "sorobanabi.I64FromJSON(j)", nil
+	case xdr.ScSpecTypeScSpecTypeU128:
+		return "*big.Int", "sorobanabi.U128(v)", "sorobanabi.U128FromJSON(j)", nil
+	case xdr.ScSpecTypeScSpecTypeI128:
+		return "*big.Int", "sorobanabi.I128(v)", "sorobanabi.I128FromJSON(j)", nil
+	case xdr.ScSpecTypeScSpecTypeString:
+		return "string", "sorobanabi.String(v)", "sorobanabi.StringFromJSON(j)", nil
+	case xdr.ScSpecTypeScSpecTypeSymbol:
+		return "string", "sorobanabi.Symbol(v)", "sorobanabi.SymbolFromJSON(j)", nil
+	case xdr.ScSpecTypeScSpecTypeBytes:
+		return "[]byte", "sorobanabi.Bytes(v)", "sorobanabi.BytesFromJSON(j)", nil
+	case xdr.ScSpecTypeScSpecTypeAddress:
+		return "string", "sorobanabi.Address(v)", "sorobanabi.AddressFromJSON(j)", nil
+
+	case xdr.ScSpecTypeScSpecTypeVec:
+		elemGoType, elemDecode, elemDecodeJSON, err := typeDefToGo(t.MustVec().ElementType)
+		if err != nil {
+			return "", "", "", fmt.Errorf("vec element: %w", err)
+		}
+		return "[]" + elemGoType,
+			fmt.Sprintf("sorobanabi.Vec(func(v xdr.ScVal) (%s, error) { return %s })(v)", elemGoType, elemDecode),
+			fmt.Sprintf("sorobanabi.VecFromJSON(func(j interface{}) (%s, error) { return %s })(j)", elemGoType, elemDecodeJSON),
+			nil
+
+	case xdr.ScSpecTypeScSpecTypeOption:
+		elemGoType, elemDecode, elemDecodeJSON, err := typeDefToGo(t.MustOption().ValueType)
+		if err != nil {
+			return "", "", "", fmt.Errorf("option value: %w", err)
+		}
+		return "*" + elemGoType,
+			fmt.Sprintf("sorobanabi.Option(func(v xdr.ScVal) (%s, error) { return %s })(v)", elemGoType, elemDecode),
+			fmt.Sprintf("sorobanabi.OptionFromJSON(func(j interface{}) (%s, error) { return %s })(j)", elemGoType, elemDecodeJSON),
+			nil
+
+	case xdr.ScSpecTypeScSpecTypeUdt:
+		name := string(t.MustUdt().Name)
+		return name, fmt.Sprintf("Decode%s(v)", name), fmt.Sprintf("Decode%sFromJSON(j)", name), nil
+
+	default:
+		return "", "", "", fmt.Errorf("unsupported spec type %v", t.Type)
+	}
+}
+
+// exportName turns a spec identifier (typically snake_case or
+// camelCase) into an exported Go identifier.
+func exportName(name string) string {
+	if name == "" {
+		return name
+	}
+	runes := []rune(name)
+	out := make([]rune, 0, len(runes))
+	upperNext := true
+	for _, r := range runes {
+		if r == '_' {
+			upperNext = true
+			continue
+		}
+		if upperNext {
+			out = append(out, toUpper(r))
+			upperNext = false
+		} else {
+			out = append(out, r)
+		}
+	}
+	return string(out)
+}
+
+func toUpper(r rune) rune {
+	if r >= 'a' && r <= 'z' {
+		return r - ('a' - 'A')
+	}
+	return r
+}
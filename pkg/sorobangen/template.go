@@ -0,0 +1,174 @@
+package sorobangen
+
+// sourceTemplate renders a Spec into a Go source file. Struct/union
+// decoders operate on a raw xdr.ScVal; Filter<FunctionName> methods
+// decode through the JSON form contract_invocation.Origin.Subscribe
+// actually exposes (see sorobanabi's FromJSON doc comment for why, and
+// its precision caveat for i128/u128 values).
+const sourceTemplate = `// Code generated by sorobangen. DO NOT EDIT.
+
+package {{.PackageName}}
+
+import (
+	{{if needsJSON .}}"encoding/json"
+	{{end}}{{if needsFmt .}}"fmt"
+	{{end}}{{if needsBigInt .}}"math/big"
+	{{end}}{{if needsXdr .}}
+	"github.com/stellar/go-stellar-sdk/xdr"
+	{{end}}
+	contract_invocation "github.com/withObsrvr/nebu-processor-registry/processors/contract-invocation"
+	{{if len .Functions}}cipb "github.com/withObsrvr/nebu-processor-registry/processors/contract-invocation/proto"
+	{{end}}{{if needsSorobanabi .}}"github.com/withObsrvr/nebu-processor-registry/pkg/sorobanabi"
+	{{end}}
+)
+
+{{range .Structs}}{{$struct := .}}
+// {{.Name}} is a generated decode target for the contract's {{.Name}} struct.
+type {{.Name}} struct {
+{{range .Fields}}	{{.Name}} {{.GoType}}
+{{end}}}
+
+// Decode{{.Name}} decodes a {{.Name}} from its Soroban struct-as-map representation.
+func Decode{{.Name}}(val xdr.ScVal) ({{.Name}}, error) {
+	var out {{.Name}}
+	m := val.MustMap()
+	if m == nil {
+		return out, fmt.Errorf("{{.Name}}: expected a map")
+	}
+	fields := make(map[string]xdr.ScVal, len(*m))
+	for _, entry := range *m {
+		fields[sorobanabi.MapKey(entry.Key)] = entry.Val
+	}
+{{range .Fields}}	if v, ok := fields["{{.SpecName}}"]; ok {
+		decoded, err := func(v xdr.ScVal) ({{.GoType}}, error) { return {{.Decode}} }(v)
+		if err != nil {
+			return out, fmt.Errorf("{{$struct.Name}}.{{.Name}}: %w", err)
+		}
+		out.{{.Name}} = decoded
+	}
+{{end}}	return out, nil
+}
+
+// Decode{{.Name}}FromJSON decodes a {{.Name}} from its ConvertScValToJSON-encoded form.
+func Decode{{.Name}}FromJSON(j interface{}) ({{.Name}}, error) {
+	var out {{.Name}}
+	m, ok := j.(map[string]interface{})
+	if !ok {
+		return out, fmt.Errorf("{{.Name}}: expected a JSON object")
+	}
+{{range .Fields}}	if j, ok := m["{{.SpecName}}"]; ok {
+		decoded, err := func(j interface{}) ({{.GoType}}, error) { return {{.DecodeJSON}} }(j)
+		if err != nil {
+			return out, fmt.Errorf("{{$struct.Name}}.{{.Name}}: %w", err)
+		}
+		out.{{.Name}} = decoded
+	}
+{{end}}	return out, nil
+}
+{{end}}
+
+{{range .Unions}}{{$union := .}}
+// {{.Name}} is a generated decode target for the contract's {{.Name}} union,
+// represented as a Case discriminant plus one nil-able pointer field per case.
+type {{.Name}} struct {
+	Case string
+{{range .Cases}}	{{.Name}} *{{$union.Name}}{{.Name}}Case
+{{end}}}
+{{range .Cases}}{{if .Fields}}
+type {{$union.Name}}{{.Name}}Case struct {
+{{range .Fields}}	{{.Name}} {{.GoType}}
+{{end}}}
+{{end}}{{end}}
+
+// Decode{{.Name}} decodes a {{.Name}} from its Soroban tagged-vec representation
+// (the case discriminant at index 0, followed by the case's tuple fields).
+func Decode{{.Name}}(val xdr.ScVal) ({{.Name}}, error) {
+	var out {{.Name}}
+	vec := val.MustVec()
+	if vec == nil || len(*vec) == 0 {
+		return out, fmt.Errorf("{{.Name}}: expected a non-empty vec")
+	}
+	caseName := sorobanabi.MapKey((*vec)[0])
+	switch caseName {
+{{range .Cases}}	case "{{.SpecName}}":
+		out.Case = "{{.Name}}"
+{{if .Fields}}		c := &{{$union.Name}}{{.Name}}Case{}
+{{range $i, $f := .Fields}}		{
+			v := (*vec)[{{add $i 1}}]
+			decoded, err := func(v xdr.ScVal) ({{$f.GoType}}, error) { return {{$f.Decode}} }(v)
+			if err != nil {
+				return out, fmt.Errorf("{{$union.Name}}.{{$f.Name}}: %w", err)
+			}
+			c.{{$f.Name}} = decoded
+		}
+{{end}}		out.{{.Name}} = c
+{{end}}
+{{end}}	default:
+		return out, fmt.Errorf("{{.Name}}: unknown case %q", caseName)
+	}
+	return out, nil
+}
+{{end}}
+
+// Processor wraps a contract_invocation.Origin with typed, spec-derived
+// Filter<FunctionName> accessors, analogous to abigen's per-event
+// Filter/Watch methods.
+type Processor struct {
+	*contract_invocation.Origin
+}
+
+// NewProcessor wraps origin with this contract's typed accessors.
+func NewProcessor(origin *contract_invocation.Origin) *Processor {
+	return &Processor{Origin: origin}
+}
+{{range .Functions}}{{$fn := .}}
+// {{.Name}}Invocation is a typed view of a "{{.SpecName}}" contract
+// invocation. Fields are decoded from contract_invocation's
+// already-JSON-encoded Arguments, not from a raw xdr.ScVal — see
+// sorobanabi's FromJSON doc comment for the resulting precision caveat
+// on i128/u128 arguments.
+type {{.Name}}Invocation struct {
+	Invocation *cipb.ContractInvocation
+{{range .Inputs}}	{{.Name}} {{.GoType}}
+{{end}}}
+
+// Filter{{.Name}} subscribes to "{{.SpecName}}" invocations on p, decoding
+// each one's arguments into a {{.Name}}Invocation. An invocation whose
+// arguments don't decode cleanly is dropped rather than surfaced as a
+// partial/zero-value struct.
+func (p *Processor) Filter{{.Name}}(query contract_invocation.FilterQuery) (<-chan {{.Name}}Invocation, contract_invocation.CancelFunc) {
+	query.FunctionNames = append(query.FunctionNames, "{{.SpecName}}")
+	raw, cancel := p.Subscribe(query)
+
+	out := make(chan {{.Name}}Invocation, cap(raw))
+	go func() {
+		defer close(out)
+		for invocation := range raw {
+			typed, err := decode{{.Name}}Invocation(invocation)
+			if err != nil {
+				continue
+			}
+			out <- typed
+		}
+	}()
+
+	return out, cancel
+}
+
+func decode{{.Name}}Invocation(invocation *cipb.ContractInvocation) ({{.Name}}Invocation, error) {
+	out := {{.Name}}Invocation{Invocation: invocation}
+{{range $i, $f := .Inputs}}	if {{$i}} < len(invocation.Arguments) {
+		var j interface{}
+		if err := json.Unmarshal([]byte(invocation.Arguments[{{$i}}]), &j); err != nil {
+			return out, fmt.Errorf("{{$fn.Name}}.{{$f.Name}}: %w", err)
+		}
+		decoded, err := func(j interface{}) ({{$f.GoType}}, error) { return {{$f.DecodeJSON}} }(j)
+		if err != nil {
+			return out, fmt.Errorf("{{$fn.Name}}.{{$f.Name}}: %w", err)
+		}
+		out.{{$f.Name}} = decoded
+	}
+{{end}}	return out, nil
+}
+{{end}}
+`
@@ -0,0 +1,188 @@
+// Package main provides a standalone CLI for the address-filter transform
+// processor. In its default mode it's a generic allow/deny list check on
+// any dot-notation event field (a Stellar address, an asset code, ...),
+// backed by the shared filter.List so usdc-filter and amount-filter's
+// hard-coded conditions don't need to be duplicated for every new
+// list-based rule. In --watchlist mode it instead matches a
+// TokenTransferEvent's relevant party address(es) (From/To, or the single
+// active party for Mint/Burn/Clawback/Fee) against a labeled watchlist,
+// keeping only matches and annotating EventMeta with the label that
+// caused the match so downstream sinks can group by cohort.
+//
+// Usage:
+//
+//	# Keep only transfers to/from a watched set of addresses
+//	token-transfer --start-ledger 60200000 --end-ledger 60200100 | \
+//	  address-filter --field to --list-source file:///etc/nebu/watchlist.txt
+//
+//	# Drop events from a denylist kept in Postgres, refreshed every minute
+//	cat events.jsonl | address-filter --field from --mode deny \
+//	  --list-source 'pg://user:pass@host:5432/db?query=SELECT+address+FROM+denylist' \
+//	  --refresh-interval 1m
+//
+//	# Track a cohort of labeled addresses (exchanges, treasuries, ...),
+//	# annotating matches and exposing a runtime admin endpoint to add or
+//	# remove entries without restarting the pipeline
+//	token-transfer --start-ledger 60200000 --end-ledger 60200100 | \
+//	  address-filter --watchlist --list-source file:///etc/nebu/watchlist.yaml \
+//	    --admin-addr :8090 --admin-token "$ADMIN_TOKEN" | \
+//	  json-file-sink --out cohort-activity.jsonl
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/withObsrvr/nebu-processor-registry/pkg/filter"
+	"github.com/withObsrvr/nebu-processor-registry/pkg/metrics"
+	address_filter "github.com/withObsrvr/nebu-processor-registry/processors/address-filter"
+	"github.com/withObsrvr/nebu/pkg/processor/cli"
+)
+
+var version = "0.2.0"
+
+var (
+	field           string
+	mode            string
+	listSource      string
+	refreshInterval time.Duration
+	watchFile       bool
+	watchlistMode   bool
+	adminAddr       string
+	adminToken      string
+
+	list      *filter.List
+	watchlist *address_filter.Watchlist
+	stop      chan struct{}
+)
+
+func main() {
+	config := cli.TransformConfig{
+		Name:        "address-filter",
+		Description: "Keep or drop events by address/asset allow/deny list, or by a labeled account watchlist",
+		Version:     version,
+	}
+
+	cli.RunTransformCLI(config, applyList, addFlags)
+}
+
+func addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&field, "field", "to", "Dot-notation event field to check, e.g. 'to' or 'transfer.asset.issuedAsset.assetCode' (ignored in --watchlist mode)")
+	cmd.Flags().StringVar(&mode, "mode", "allow", "List mode: 'allow' (keep matches) or 'deny' (drop matches) (ignored in --watchlist mode)")
+	cmd.Flags().StringVar(&listSource, "list-source", "", "List source DSN: file://path, http://url, or pg://dsn?query=... (required; --watchlist mode only supports file://, loaded as labeled YAML/JSON)")
+	cmd.Flags().DurationVar(&refreshInterval, "refresh-interval", time.Minute, "How often to reload --list-source")
+	cmd.Flags().BoolVar(&watchFile, "watch-file", true, "For file:// sources, also reload on file change and on SIGHUP")
+	cmd.Flags().BoolVar(&watchlistMode, "watchlist", false, "Match TokenTransferEvent parties against a labeled watchlist instead of a single --field allow/deny check")
+	cmd.Flags().StringVar(&adminAddr, "admin-addr", "", "If set, serve a runtime watchlist admin endpoint (POST/DELETE /watchlist) on this address (--watchlist mode only)")
+	cmd.Flags().StringVar(&adminToken, "admin-token", getEnvOrDefault("ADMIN_TOKEN", ""),
+		"Bearer token required on every --admin-addr request (or set ADMIN_TOKEN env); the admin endpoint refuses to start without one")
+	cmd.MarkFlagRequired("list-source")
+}
+
+// applyList lazily initializes the list/watchlist on first event, starts
+// its refresh watchers and (in --watchlist mode) its admin endpoint, and
+// then runs every event through it.
+func applyList(event map[string]interface{}) (map[string]interface{}, error) {
+	if list == nil {
+		if err := initList(); err != nil {
+			return nil, err
+		}
+	}
+
+	if watchlistMode {
+		keep, out, err := watchlist.Apply(event)
+		if err != nil || !keep {
+			return nil, err
+		}
+		return out, nil
+	}
+
+	keep, out, err := list.Apply(event)
+	if err != nil {
+		return nil, err
+	}
+	if !keep {
+		return nil, nil
+	}
+	return out, nil
+}
+
+func initList() error {
+	reg := metrics.NewRegistry("address-filter")
+	matched, rejected := reg.ListCounters()
+
+	if watchlistMode {
+		list = filter.NewList("", filter.ListAllow, matched, rejected)
+		list.SetLoader(func(src string) error {
+			if hasScheme(src, "file") {
+				src = src[len("file://"):]
+			}
+			return list.LoadLabeledFile(src)
+		})
+		watchlist = address_filter.NewWatchlist(list)
+	} else {
+		listMode := filter.ListAllow
+		if mode == string(filter.ListDeny) {
+			listMode = filter.ListDeny
+		} else if mode != string(filter.ListAllow) {
+			return fmt.Errorf("invalid --mode %q: expected allow or deny", mode)
+		}
+		list = filter.NewList(field, listMode, matched, rejected)
+	}
+
+	if err := list.LoadSource(listSource); err != nil {
+		return fmt.Errorf("failed to load --list-source: %w", err)
+	}
+
+	stop = make(chan struct{})
+	onError := func(err error) { fmt.Fprintf(os.Stderr, "address-filter: reload failed: %v\n", err) }
+	list.WatchSource(listSource, refreshInterval, stop, onError)
+	list.ReloadOnSIGHUP(listSource, stop, onError)
+
+	if watchFile && hasScheme(listSource, "file") {
+		path := listSource[len("file://"):]
+		if err := list.WatchFSNotify(path, stop, onError); err != nil {
+			fmt.Fprintf(os.Stderr, "address-filter: file watch disabled: %v\n", err)
+		}
+	}
+
+	if watchlistMode && adminAddr != "" {
+		if adminToken == "" {
+			return fmt.Errorf("--admin-token (or ADMIN_TOKEN) is required when --admin-addr is set: refusing to serve an unauthenticated watchlist admin endpoint")
+		}
+		serveAdmin()
+	}
+
+	return nil
+}
+
+// serveAdmin starts the watchlist admin HTTP endpoint in the background.
+// A listen failure is logged, not fatal — the pipeline keeps running
+// without runtime mutation.
+func serveAdmin() {
+	handler := list.AdminHandler("/watchlist", adminToken)
+	server := &http.Server{Addr: adminAddr, Handler: handler}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			fmt.Fprintf(os.Stderr, "address-filter: admin endpoint failed: %v\n", err)
+		}
+	}()
+	go func() {
+		<-stop
+		server.Close()
+	}()
+}
+
+func hasScheme(dsn, scheme string) bool {
+	return len(dsn) > len(scheme)+3 && dsn[:len(scheme)+3] == scheme+"://"
+}
+
+func getEnvOrDefault(key, defaultValue string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return defaultValue
+}
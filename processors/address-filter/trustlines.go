@@ -0,0 +1,65 @@
+package address_filter
+
+import (
+	"github.com/stellar/go-stellar-sdk/ingest"
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+// TrustlineHolders walks changes for TrustLine ledger entries whose asset
+// issuer is one of issuers, returning a map from holder account address
+// to the label issuers associates with that issuer. It's the matching
+// half of bootstrapping a watchlist from "every account that holds a
+// trustline to one of these issuers" — the driving loop that supplies
+// changes by scanning a live range of ledgers needs a ledger backend this
+// transform processor doesn't have direct access to (transforms in this
+// pipeline read newline-delimited JSON on stdin, not raw ledgers), so
+// that loop is left to whatever origin-side tooling feeds this function,
+// the same way ttl.go's extractTtlExtensions is handed changes by an
+// Origin that already has them rather than sourcing them itself.
+func TrustlineHolders(changes []ingest.Change, issuers map[string]string) map[string]string {
+	holders := make(map[string]string)
+
+	for _, change := range changes {
+		if change.Type != xdr.LedgerEntryTypeTrustline {
+			continue
+		}
+
+		entry := change.Post
+		if entry == nil {
+			entry = change.Pre // a removed trustline still identifies a past holder
+		}
+		if entry == nil || entry.Data.TrustLine == nil {
+			continue
+		}
+
+		line := entry.Data.TrustLine
+		issuer, ok := trustlineIssuer(line.Asset)
+		if !ok {
+			continue
+		}
+
+		label, watched := issuers[issuer]
+		if !watched {
+			continue
+		}
+
+		holders[line.AccountId.Address()] = label
+	}
+
+	return holders
+}
+
+// trustlineIssuer extracts a credit asset trustline's issuer address. Pool
+// share trustlines have no single issuer and are reported as not-ok.
+func trustlineIssuer(asset xdr.TrustLineAsset) (string, bool) {
+	switch asset.Type {
+	case xdr.AssetTypeAssetTypeCreditAlphanum4:
+		alphaNum4 := asset.MustAlphaNum4()
+		return alphaNum4.Issuer.Address(), true
+	case xdr.AssetTypeAssetTypeCreditAlphanum12:
+		alphaNum12 := asset.MustAlphaNum12()
+		return alphaNum12.Issuer.Address(), true
+	default:
+		return "", false
+	}
+}
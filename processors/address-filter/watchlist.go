@@ -0,0 +1,99 @@
+// Package address_filter provides the party-matching logic for the
+// address-filter transform processor: which TokenTransferEvent field(s)
+// carry the address relevant to a watchlist, given the event's oneof
+// kind, and how a match gets annotated back onto the event for
+// downstream sinks to group by cohort.
+package address_filter
+
+import "strings"
+
+// relevantPartyFields lists, per TokenTransferEvent oneof kind (the
+// top-level key the event uses), the dot-notation field(s) carrying the
+// address a watchlist match should be checked against — both sides of a
+// transfer, or the single active party for mint/burn/clawback/fee.
+var relevantPartyFields = map[string][]string{
+	"transfer": {"transfer.from", "transfer.to"},
+	"mint":     {"mint.to"},
+	"burn":     {"burn.from"},
+	"clawback": {"clawback.from"},
+	"fee":      {"fee.from"},
+}
+
+// Lister is the subset of filter.List's API Watchlist needs — satisfied
+// by *filter.List, so Watchlist can share its loaders, watchers, and
+// admin endpoint instead of re-implementing them.
+type Lister interface {
+	Label(addr string) (string, bool)
+}
+
+// Watchlist filters TokenTransferEvents by checking the relevant party
+// address(es) for the event's kind against a Lister, annotating
+// EventMeta with the label that caused the match.
+type Watchlist struct {
+	List Lister
+}
+
+// NewWatchlist wraps list with address-filter's event-kind-aware party
+// matching.
+func NewWatchlist(list Lister) *Watchlist {
+	return &Watchlist{List: list}
+}
+
+// Apply keeps event if any of its relevant party addresses are present
+// in w.List, annotating event["meta"]["watchlistLabel"] with the first
+// matching entry's label. Events whose kind isn't one of
+// relevantPartyFields (or whose relevant fields are all absent/non-
+// string) are dropped.
+func (w *Watchlist) Apply(event map[string]interface{}) (bool, map[string]interface{}, error) {
+	for kind, fields := range relevantPartyFields {
+		if _, ok := event[kind]; !ok {
+			continue
+		}
+
+		for _, field := range fields {
+			addr, ok := getNestedValue(event, field)
+			if !ok {
+				continue
+			}
+			s, ok := addr.(string)
+			if !ok || s == "" {
+				continue
+			}
+			if label, found := w.List.Label(s); found {
+				annotateLabel(event, label)
+				return true, event, nil
+			}
+		}
+		return false, nil, nil
+	}
+
+	return false, nil, nil
+}
+
+func annotateLabel(event map[string]interface{}, label string) {
+	meta, ok := event["meta"].(map[string]interface{})
+	if !ok {
+		meta = make(map[string]interface{})
+		event["meta"] = meta
+	}
+	meta["watchlistLabel"] = label
+}
+
+func getNestedValue(event map[string]interface{}, key string) (interface{}, bool) {
+	parts := strings.Split(key, ".")
+	current := event
+	for i, part := range parts {
+		value, ok := current[part]
+		if !ok {
+			return nil, false
+		}
+		if i == len(parts)-1 {
+			return value, true
+		}
+		current, ok = value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+	}
+	return nil, false
+}
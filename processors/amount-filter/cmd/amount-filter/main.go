@@ -23,6 +23,7 @@ package main
 
 import (
 	"github.com/spf13/cobra"
+	"github.com/withObsrvr/nebu-processor-registry/pkg/processor/otel"
 	"github.com/withObsrvr/nebu-processor-registry/processors/amount-filter"
 	"github.com/withObsrvr/nebu/pkg/processor/cli"
 )
@@ -30,9 +31,10 @@ import (
 var version = "0.1.0"
 
 var (
-	minAmount int64
-	maxAmount int64
-	assetCode string
+	minAmount        int64
+	maxAmount        int64
+	assetCode        string
+	eventPayloadExpr []string
 )
 
 func main() {
@@ -42,19 +44,23 @@ func main() {
 		Version:     version,
 	}
 
-	cli.RunTransformCLI(config, filterByAmount, addFlags)
+	cli.RunTransformCLI(config, otel.WrapTransformFunc("amount-filter", filterByAmount), addFlags)
 }
 
 func addFlags(cmd *cobra.Command) {
 	cmd.Flags().Int64Var(&minAmount, "min", 0, "Minimum amount (inclusive, in stroops)")
 	cmd.Flags().Int64Var(&maxAmount, "max", 0, "Maximum amount (inclusive, in stroops, 0 = no limit)")
 	cmd.Flags().StringVar(&assetCode, "asset", "", "Filter by asset code (optional, e.g., USDC, XLM)")
+	cmd.Flags().StringArrayVar(&eventPayloadExpr, "event-payload-expr", nil,
+		`jq expression selecting the event's payload object, repeatable and tried in order (default: .transfer, .mint, .burn, .clawback, .fee)`)
 }
 
 // filterByAmount filters events based on amount and optionally asset code.
 // Returns the event if it passes the filters, nil otherwise.
-func filterByAmount(event map[string]interface{}) map[string]interface{} {
-	// Use shared filter logic
-	filter := amount_filter.NewFilter(minAmount, maxAmount, assetCode)
-	return filter.FilterEvent(event)
+func filterByAmount(event map[string]interface{}) (map[string]interface{}, error) {
+	filter, err := amount_filter.NewFilterWithExprs(minAmount, maxAmount, assetCode, eventPayloadExpr)
+	if err != nil {
+		return nil, err
+	}
+	return filter.FilterEvent(event), nil
 }
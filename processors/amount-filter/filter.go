@@ -2,40 +2,66 @@
 // This logic is shared between CLI and gRPC implementations.
 package amount_filter
 
-import "strconv"
+import (
+	"strconv"
+
+	"github.com/withObsrvr/nebu-processor-registry/pkg/extract"
+)
+
+// defaultPayloadExprs reproduces the original hard-coded oneof probing
+// (transfer, mint, burn, clawback, fee) as jq expressions, so a Filter
+// built without --event-payload-expr behaves exactly as before.
+var defaultPayloadExprs = []string{".transfer", ".mint", ".burn", ".clawback", ".fee"}
+
+var defaultPayloadExtractor = extract.MustNew(defaultPayloadExprs)
 
 // Filter encapsulates the amount filtering configuration and logic.
 type Filter struct {
 	MinAmount int64
 	MaxAmount int64
 	AssetCode string
+
+	payload *extract.Extractor
 }
 
-// NewFilter creates a new amount filter with the given parameters.
+// NewFilter creates a new amount filter with the given parameters, probing
+// the default set of oneof fields (transfer, mint, burn, clawback, fee) for
+// its payload.
 func NewFilter(minAmount, maxAmount int64, assetCode string) *Filter {
 	return &Filter{
 		MinAmount: minAmount,
 		MaxAmount: maxAmount,
 		AssetCode: assetCode,
+		payload:   defaultPayloadExtractor,
 	}
 }
 
+// NewFilterWithExprs is like NewFilter but extracts the event payload with
+// payloadExprs (jq expressions tried in order, e.g. via repeatable
+// --event-payload-expr flags) instead of the default oneof list, so the
+// filter can follow a new protobuf oneof or custom JQ-shaped event without
+// a recompile. A nil or empty payloadExprs falls back to the default list.
+func NewFilterWithExprs(minAmount, maxAmount int64, assetCode string, payloadExprs []string) (*Filter, error) {
+	if len(payloadExprs) == 0 {
+		return NewFilter(minAmount, maxAmount, assetCode), nil
+	}
+	e, err := extract.New(payloadExprs)
+	if err != nil {
+		return nil, err
+	}
+	return &Filter{
+		MinAmount: minAmount,
+		MaxAmount: maxAmount,
+		AssetCode: assetCode,
+		payload:   e,
+	}, nil
+}
+
 // FilterEvent applies the amount filter logic to an event.
 // Returns the event if it passes the filters, nil if it should be filtered out.
 func (f *Filter) FilterEvent(event map[string]interface{}) map[string]interface{} {
-	// Extract the event data from protojson format
-	// Events can be: transfer, mint, burn, clawback, fee
-	var eventData map[string]interface{}
-	var ok bool
-
-	// Try each event type
-	for _, eventType := range []string{"transfer", "mint", "burn", "clawback", "fee"} {
-		if eventData, ok = event[eventType].(map[string]interface{}); ok {
-			break
-		}
-	}
-
-	if eventData == nil {
+	eventData, ok := f.payload.ExtractMap(event)
+	if !ok {
 		return nil // Not a recognized event type
 	}
 
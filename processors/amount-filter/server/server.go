@@ -8,6 +8,7 @@ import (
 	"io"
 	"sync"
 
+	"github.com/withObsrvr/nebu-processor-registry/pkg/filter"
 	"github.com/withObsrvr/nebu-processor-registry/processors/amount-filter"
 	pb "github.com/withObsrvr/nebu-processor-registry/processors/amount-filter/proto"
 )
@@ -17,6 +18,12 @@ type Server struct {
 	pb.UnimplementedAmountFilterServiceServer
 	mu     sync.RWMutex
 	filter *amount_filter.Filter
+
+	// addressList is an optional allow/deny list applied after the amount
+	// filter, pushed by UpdateList so distributed pipelines can update a
+	// running server's watchlist without a restart. Nil until the first
+	// UpdateList call.
+	addressList *filter.List
 }
 
 // NewServer creates a new amount filter gRPC server with default configuration.
@@ -30,12 +37,18 @@ func NewServer() *Server {
 func (s *Server) Configure(ctx context.Context, req *pb.ConfigureRequest) (*pb.ConfigureResponse, error) {
 	cfg := req.GetConfig()
 
-	s.mu.Lock()
-	s.filter = amount_filter.NewFilter(
+	newFilter, err := amount_filter.NewFilterWithExprs(
 		cfg.GetMinAmount(),
 		cfg.GetMaxAmount(),
 		cfg.GetAssetCode(),
+		cfg.GetPayloadExprs(),
 	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payload_exprs: %w", err)
+	}
+
+	s.mu.Lock()
+	s.filter = newFilter
 	s.mu.Unlock()
 
 	return &pb.ConfigureResponse{
@@ -59,6 +72,58 @@ func (s *Server) GetConfig(ctx context.Context, req *pb.GetConfigRequest) (*pb.G
 	}, nil
 }
 
+// UpdateList installs req's addresses as the server's address allow/deny
+// list, replacing whatever list (if any) was configured before. Field and
+// Mode may only change by calling UpdateList again; there is no separate
+// "configure the list shape" RPC since the list has no other settings.
+func (s *Server) UpdateList(ctx context.Context, req *pb.UpdateListRequest) (*pb.UpdateListResponse, error) {
+	mode := filter.ListMode(req.GetMode())
+	if mode != filter.ListAllow && mode != filter.ListDeny {
+		return nil, fmt.Errorf("invalid mode %q: expected allow or deny", req.GetMode())
+	}
+
+	s.mu.Lock()
+	if s.addressList == nil || s.addressList.Name() != fmt.Sprintf("list:%s:%s", mode, req.GetField()) {
+		s.addressList = filter.NewList(req.GetField(), mode, nil, nil)
+	}
+	s.addressList.Set(req.GetAddresses())
+	size := s.addressList.Size()
+	s.mu.Unlock()
+
+	return &pb.UpdateListResponse{
+		Success: true,
+		Message: fmt.Sprintf("list updated: field=%s mode=%s entries=%d", req.GetField(), req.GetMode(), size),
+		Size:    size,
+	}, nil
+}
+
+// GetList returns the server's current address list configuration.
+func (s *Server) GetList(ctx context.Context, req *pb.GetListRequest) (*pb.GetListResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.addressList == nil {
+		return &pb.GetListResponse{}, nil
+	}
+	return &pb.GetListResponse{Size: s.addressList.Size()}, nil
+}
+
+// passesAddressList reports whether event should continue past the
+// optional address list, holding s.mu only while it reads the list
+// pointer; Apply runs after the lock is released. A server with no
+// list configured (the common case) always passes.
+func (s *Server) passesAddressList(event map[string]interface{}) (bool, error) {
+	s.mu.RLock()
+	list := s.addressList
+	s.mu.RUnlock()
+
+	if list == nil {
+		return true, nil
+	}
+	keep, _, err := list.Apply(event)
+	return keep, err
+}
+
 // Transform applies the filter to a single event.
 func (s *Server) Transform(ctx context.Context, req *pb.TransformRequest) (*pb.TransformResponse, error) {
 	// Decode JSON event
@@ -79,6 +144,12 @@ func (s *Server) Transform(ctx context.Context, req *pb.TransformRequest) (*pb.T
 		}, nil
 	}
 
+	if ok, err := s.passesAddressList(result); err != nil {
+		return nil, fmt.Errorf("address list check: %w", err)
+	} else if !ok {
+		return &pb.TransformResponse{Filtered: true}, nil
+	}
+
 	// Encode result
 	resultJSON, err := json.Marshal(result)
 	if err != nil {
@@ -113,6 +184,14 @@ func (s *Server) TransformStream(stream pb.AmountFilterService_TransformStreamSe
 		result := s.filter.FilterEvent(event)
 		s.mu.RUnlock()
 
+		if result != nil {
+			if ok, err := s.passesAddressList(result); err != nil {
+				return fmt.Errorf("address list check: %w", err)
+			} else if !ok {
+				result = nil
+			}
+		}
+
 		resp := &pb.TransformResponse{
 			Filtered: result == nil,
 		}
@@ -5,18 +5,44 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"sync"
 	"time"
 
 	"github.com/stellar/go-stellar-sdk/ingest"
 	"github.com/stellar/go-stellar-sdk/strkey"
 	"github.com/stellar/go-stellar-sdk/xdr"
+	"github.com/withObsrvr/nebu-processor-registry/pkg/contractspec"
 	"github.com/withObsrvr/nebu/pkg/processor"
 )
 
+// subscriberBufferSize is the per-subscriber channel depth used by
+// Subscribe. A subscriber that falls behind by more than this many events
+// either blocks the ledger-processing loop (Filter.BlockOnFull) or starts
+// losing its oldest buffered events, depending on policy.
+const subscriberBufferSize = 256
+
 // ContractEventsOrigin processes ledgers and extracts contract events
 type ContractEventsOriginProto struct {
 	networkPassphrase string
 	out               chan *ContractEvent
+
+	subsMu sync.RWMutex
+	subs   []*subscriber
+
+	// specs is nil until RegisterSpec is first called, so contracts with no
+	// spec registered never pay for a lookup.
+	specs *contractspec.SpecRegistry
+}
+
+// RegisterSpec loads a contract's Soroban event specs (its SCSpecEntry
+// entries) so buildContractEvent can emit a TypedEvent alongside the raw
+// ContractEvent for events it finds a matching spec for. Safe to call
+// repeatedly, including to replace a contract's previously loaded spec.
+func (p *ContractEventsOriginProto) RegisterSpec(contractID string, entries []xdr.ScSpecEntry) error {
+	if p.specs == nil {
+		p.specs = contractspec.NewSpecRegistry()
+	}
+	return p.specs.LoadSpec(contractID, entries)
 }
 
 // NewContractEventsOriginProto creates a new contract events origin processor
@@ -27,17 +53,266 @@ func NewContractEventsOriginProto(networkPassphrase string) *ContractEventsOrigi
 	}
 }
 
+// Filter selects a subset of contract events for a Subscribe channel.
+// A zero-value field disables that criterion rather than matching nothing,
+// except IncludeFailedTx: an empty Filter matches every event from a
+// successful transaction, and events from failed transactions are only
+// matched when IncludeFailedTx is set.
+type Filter struct {
+	ContractIDs        []string
+	EventTypes         []string
+	TopicPatterns      [][]TopicMatcher
+	IncludeFailedTx    bool
+	IncludeDiagnostics bool
+
+	// BlockOnFull makes Subscribe's channel apply backpressure to ledger
+	// processing when the subscriber falls behind, instead of the default
+	// drop-oldest policy.
+	BlockOnFull bool
+}
+
+// TopicMatcher matches a single, positional entry of event.Body.V0.Topics.
+// The zero value is a wildcard that matches any topic in that position.
+type TopicMatcher struct {
+	Symbol  string   // exact xdr.ScValTypeScvSymbol match
+	Address string   // exact xdr.ScValTypeScvAddress match (strkey-encoded)
+	AnyOf   []string // matches if the topic's symbol or address is any of these
+}
+
+func (m TopicMatcher) isWildcard() bool {
+	return m.Symbol == "" && m.Address == "" && len(m.AnyOf) == 0
+}
+
+func (m TopicMatcher) matches(topic xdr.ScVal) bool {
+	if m.isWildcard() {
+		return true
+	}
+	switch topic.Type {
+	case xdr.ScValTypeScvSymbol:
+		sym := string(topic.MustSym())
+		if m.Symbol == sym {
+			return true
+		}
+		for _, v := range m.AnyOf {
+			if v == sym {
+				return true
+			}
+		}
+	case xdr.ScValTypeScvAddress:
+		addr, err := addressToString(topic.MustAddress())
+		if err != nil {
+			return false
+		}
+		if m.Address == addr {
+			return true
+		}
+		for _, v := range m.AnyOf {
+			if v == addr {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// subscriber is one Subscribe call's registration: its filter, its
+// delivery channel, and its overflow policy.
+type subscriber struct {
+	filter Filter
+	ch     chan *ContractEvent
+}
+
+// Subscribe registers a new, independently-filtered stream of contract
+// events alongside Out(), so a consumer that only cares about e.g.
+// transfer events from one contract doesn't have to pull and discard
+// every event off the shared channel. The returned channel is closed once
+// ctx is done.
+func (p *ContractEventsOriginProto) Subscribe(ctx context.Context, filter Filter) <-chan *ContractEvent {
+	sub := &subscriber{
+		filter: filter,
+		ch:     make(chan *ContractEvent, subscriberBufferSize),
+	}
+
+	p.subsMu.Lock()
+	p.subs = append(p.subs, sub)
+	p.subsMu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		p.unsubscribe(sub)
+		close(sub.ch)
+	}()
+
+	return sub.ch
+}
+
+func (p *ContractEventsOriginProto) unsubscribe(sub *subscriber) {
+	p.subsMu.Lock()
+	defer p.subsMu.Unlock()
+	for i, s := range p.subs {
+		if s == sub {
+			p.subs = append(p.subs[:i], p.subs[i+1:]...)
+			return
+		}
+	}
+}
+
+// publish fans a just-built contract event out to every subscriber whose
+// filter matches. Topic patterns are matched against the raw XDR topics
+// (not contractEvent.TopicDecoded) so a subscriber that doesn't match
+// never pays for proto-decoded topic comparisons.
+func (p *ContractEventsOriginProto) publish(contractEvent *ContractEvent, rawTopics []xdr.ScVal, successful bool) {
+	// Snapshot the subscriber list and release subsMu before sending:
+	// a BlockOnFull subscriber's send below can block indefinitely, and
+	// holding subsMu.RLock() across that would wedge every concurrent
+	// Subscribe/unsubscribe call (RWMutex blocks new readers once a writer
+	// is waiting), including the unsubscribe a cancelled subscriber's own
+	// cleanup goroutine needs to run.
+	p.subsMu.RLock()
+	subs := make([]*subscriber, len(p.subs))
+	copy(subs, p.subs)
+	p.subsMu.RUnlock()
+
+	for _, sub := range subs {
+		if !matchesFilter(sub.filter, contractEvent, rawTopics, successful) {
+			continue
+		}
+
+		ev := contractEvent
+		if !sub.filter.IncludeDiagnostics {
+			ev = stripDiagnostics(ev)
+		}
+
+		if sub.filter.BlockOnFull {
+			sub.ch <- ev
+			continue
+		}
+
+		select {
+		case sub.ch <- ev:
+		default:
+			// Drop-oldest: evict one buffered event and retry once so a
+			// slow subscriber loses history instead of stalling ledger
+			// processing for everyone else.
+			select {
+			case <-sub.ch:
+			default:
+			}
+			select {
+			case sub.ch <- ev:
+			default:
+			}
+		}
+	}
+}
+
+// matchesFilter reports whether contractEvent passes filter's criteria.
+func matchesFilter(filter Filter, contractEvent *ContractEvent, rawTopics []xdr.ScVal, successful bool) bool {
+	if !successful && !filter.IncludeFailedTx {
+		return false
+	}
+	if len(filter.ContractIDs) > 0 && !containsString(filter.ContractIDs, contractEvent.ContractId) {
+		return false
+	}
+	if len(filter.EventTypes) > 0 && !containsString(filter.EventTypes, contractEvent.EventType) {
+		return false
+	}
+	if len(filter.TopicPatterns) > 0 && !anyPatternMatches(filter.TopicPatterns, rawTopics) {
+		return false
+	}
+	return true
+}
+
+func anyPatternMatches(patterns [][]TopicMatcher, topics []xdr.ScVal) bool {
+	for _, pattern := range patterns {
+		if patternMatches(pattern, topics) {
+			return true
+		}
+	}
+	return false
+}
+
+func patternMatches(pattern []TopicMatcher, topics []xdr.ScVal) bool {
+	if len(pattern) > len(topics) {
+		return false
+	}
+	for i, m := range pattern {
+		if !m.matches(topics[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(haystack []string, needle string) bool {
+	for _, s := range haystack {
+		if s == needle {
+			return true
+		}
+	}
+	return false
+}
+
+// stripDiagnostics returns ev with DiagnosticEvents cleared, copying only
+// when necessary so subscribers that don't want diagnostic payloads don't
+// carry that cost through their channel while Out() and other subscribers
+// still see the full event.
+func stripDiagnostics(ev *ContractEvent) *ContractEvent {
+	if ev.DiagnosticEvents == nil {
+		return ev
+	}
+	cp := *ev
+	cp.DiagnosticEvents = nil
+	return &cp
+}
+
 // ProcessLedger implements processor.Origin
 func (p *ContractEventsOriginProto) ProcessLedger(ctx context.Context, ledger xdr.LedgerCloseMeta) error {
+	events, err := p.extractLedger(ledger)
+	if err != nil {
+		return err
+	}
+
+	for _, le := range events {
+		p.publish(le.event, le.rawTopics, le.successful)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case p.out <- le.event:
+			// Event sent
+		}
+	}
+
+	return nil
+}
+
+// ledgerEvent pairs a built ContractEvent with the raw inputs publish
+// needs (xdr topics and tx success) without re-deriving them from the
+// already-decoded proto form.
+type ledgerEvent struct {
+	event      *ContractEvent
+	rawTopics  []xdr.ScVal
+	successful bool
+}
+
+// extractLedger reads every contract event out of ledger and builds their
+// ContractEvent protobufs, without pushing anything to Out() or
+// subscribers. ProcessLedger is a thin wrapper around it for the normal
+// streaming path; Replay calls it directly so a worker can extract a
+// ledger's events independently of the shared out channel.
+func (p *ContractEventsOriginProto) extractLedger(ledger xdr.LedgerCloseMeta) ([]ledgerEvent, error) {
 	txReader, err := ingest.NewLedgerTransactionReaderFromLedgerCloseMeta(p.networkPassphrase, ledger)
 	if err != nil {
-		return fmt.Errorf("error creating transaction reader: %w", err)
+		return nil, fmt.Errorf("error creating transaction reader: %w", err)
 	}
 	defer txReader.Close()
 
 	ledgerSeq := ledger.LedgerSequence()
 	closeTime := int64(ledger.LedgerHeaderHistoryEntry().Header.ScpValue.CloseTime)
 
+	var events []ledgerEvent
+
 	// Process each transaction
 	txIndex := uint32(0)
 	for {
@@ -46,7 +321,7 @@ func (p *ContractEventsOriginProto) ProcessLedger(ctx context.Context, ledger xd
 			break
 		}
 		if err != nil {
-			return fmt.Errorf("error reading transaction: %w", err)
+			return nil, fmt.Errorf("error reading transaction: %w", err)
 		}
 
 		// Get transaction events using SDK helper (handles V3/V4 compatibility)
@@ -91,12 +366,11 @@ func (p *ContractEventsOriginProto) ProcessLedger(ctx context.Context, ledger xd
 					continue
 				}
 
-				select {
-				case <-ctx.Done():
-					return ctx.Err()
-				case p.out <- contractEvent:
-					// Event sent
-				}
+				events = append(events, ledgerEvent{
+					event:      contractEvent,
+					rawTopics:  event.Body.V0.Topics,
+					successful: successful,
+				})
 			}
 		}
 
@@ -118,19 +392,18 @@ func (p *ContractEventsOriginProto) ProcessLedger(ctx context.Context, ledger xd
 					continue
 				}
 
-				select {
-				case <-ctx.Done():
-					return ctx.Err()
-				case p.out <- contractEvent:
-					// Event sent
-				}
+				events = append(events, ledgerEvent{
+					event:      contractEvent,
+					rawTopics:  txEvent.Event.Body.V0.Topics,
+					successful: successful,
+				})
 			}
 		}
 
 		txIndex++
 	}
 
-	return nil
+	return events, nil
 }
 
 // buildContractEvent constructs a ContractEvent protobuf from XDR data
@@ -245,13 +518,83 @@ func (p *ContractEventsOriginProto) buildContractEvent(
 		contractEvent.DiagnosticEvents = diagEvents
 	}
 
+	// Typed decoding is best-effort: no registered spec (or an arity
+	// mismatch against the spec found) just leaves TypedEvent nil and
+	// callers fall back to the raw TopicDecoded/DataDecoded tree.
+	contractEvent.TypedEvent = p.buildTypedEvent(contractID, event.Body.V0.Topics, topicDecoded, dataDecoded)
+
 	return contractEvent, nil
 }
 
+// buildTypedEvent looks up contractID's spec (if any) by the event's
+// discriminant symbol (its first topic) and, if the topic arity matches
+// what the spec declares, decodes topics and data into named fields. It
+// returns nil whenever no spec is registered, the first topic isn't a
+// symbol, or the arity doesn't match — buildContractEvent treats all of
+// these as "fall back to the raw event".
+func (p *ContractEventsOriginProto) buildTypedEvent(contractID string, rawTopics []xdr.ScVal, topicDecoded []*ScVal, dataDecoded *ScVal) *TypedEvent {
+	if p.specs == nil || len(rawTopics) == 0 {
+		return nil
+	}
+	if rawTopics[0].Type != xdr.ScValTypeScvSymbol {
+		return nil
+	}
+
+	spec, ok := p.specs.Lookup(contractID, string(rawTopics[0].MustSym()))
+	if !ok {
+		return nil
+	}
+	if spec.topicArity() != len(rawTopics) {
+		// Spec doesn't describe this call shape; not safe to assume field
+		// positions line up.
+		return nil
+	}
+
+	fields := make(map[string]*ScVal, len(spec.TopicParams)+len(spec.DataParams))
+	for i, name := range spec.TopicParams {
+		fields[name] = topicDecoded[spec.PrefixTopicCount+i]
+	}
+
+	switch spec.DataFormat {
+	case contractspec.DataFormatMap:
+		if mv := dataDecoded.GetMapValue(); mv != nil {
+			for _, entry := range mv.Entries {
+				if sym := entry.Key.GetSymbolValue(); sym != "" {
+					fields[sym] = entry.Val
+				}
+			}
+		}
+	case contractspec.DataFormatVec:
+		if vv := dataDecoded.GetVecValue(); vv != nil {
+			for i, v := range vv.Values {
+				if i < len(spec.DataParams) {
+					fields[spec.DataParams[i]] = v
+				}
+			}
+		}
+	default: // DataFormatSingleValue
+		if len(spec.DataParams) > 0 {
+			fields[spec.DataParams[0]] = dataDecoded
+		}
+	}
+
+	return &TypedEvent{
+		ContractId: contractID,
+		EventName:  spec.Name,
+		Fields:     fields,
+	}
+}
+
 // detectEventTypeFromTopics attempts to determine the event type from topics
 func detectEventTypeFromTopics(topics []xdr.ScVal) string {
 	// Check topics for common event type patterns
 	for _, topic := range topics {
+		if topic.Type == xdr.ScValTypeScvError {
+			// Failed contract calls surface their error as a topic rather
+			// than a symbol; label it explicitly instead of falling
+			// through to "unknown" below.
+			return "error"
+		}
 		if topic.Type == xdr.ScValTypeScvSymbol {
 			sym := string(topic.MustSym())
 			// Return the first symbol as the event type
@@ -341,19 +684,11 @@ func convertXdrScValToProto(val xdr.ScVal) *ScVal {
 		}
 		return &ScVal{Value: &ScVal_MapValue{MapValue: &ScMap{Entries: entries}}}
 	case xdr.ScValTypeScvAddress:
-		addr := val.MustAddress()
-		switch addr.Type {
-		case xdr.ScAddressTypeScAddressTypeAccount:
-			accountID := addr.MustAccountId()
-			return &ScVal{Value: &ScVal_AddressValue{AddressValue: accountID.Address()}}
-		case xdr.ScAddressTypeScAddressTypeContract:
-			contractID := addr.MustContractId()
-			encoded, err := strkey.Encode(strkey.VersionByteContract, contractID[:])
-			if err != nil {
-				return &ScVal{Value: &ScVal_VoidValue{VoidValue: &ScVoid{}}}
-			}
-			return &ScVal{Value: &ScVal_AddressValue{AddressValue: encoded}}
+		encoded, err := addressToString(val.MustAddress())
+		if err != nil {
+			return &ScVal{Value: &ScVal_VoidValue{VoidValue: &ScVoid{}}}
 		}
+		return &ScVal{Value: &ScVal_AddressValue{AddressValue: encoded}}
 	case xdr.ScValTypeScvLedgerKeyContractInstance:
 		return &ScVal{Value: &ScVal_LedgerKeyValue{LedgerKeyValue: "contract_instance"}}
 	case xdr.ScValTypeScvLedgerKeyNonce:
@@ -363,11 +698,71 @@ func convertXdrScValToProto(val xdr.ScVal) *ScVal {
 		return &ScVal{Value: &ScVal_TimepointValue{TimepointValue: tp.Format(time.RFC3339)}}
 	case xdr.ScValTypeScvDuration:
 		return &ScVal{Value: &ScVal_DurationValue{DurationValue: uint64(val.MustDuration())}}
+	case xdr.ScValTypeScvError:
+		return &ScVal{Value: &ScVal_ErrorValue{ErrorValue: convertXdrScErrorToProto(val.MustError())}}
 	}
 
 	return &ScVal{Value: &ScVal_VoidValue{VoidValue: &ScVoid{}}}
 }
 
+// addressToString strkey-encodes an xdr.ScAddress, shared by
+// convertXdrScValToProto and TopicMatcher so both decode addresses the
+// same way.
+func addressToString(addr xdr.ScAddress) (string, error) {
+	switch addr.Type {
+	case xdr.ScAddressTypeScAddressTypeAccount:
+		accountID := addr.MustAccountId()
+		return accountID.Address(), nil
+	case xdr.ScAddressTypeScAddressTypeContract:
+		contractID := addr.MustContractId()
+		return strkey.Encode(strkey.VersionByteContract, contractID[:])
+	}
+	return "", fmt.Errorf("unsupported ScAddress type %v", addr.Type)
+}
+
+// convertXdrScErrorToProto converts an XDR ScError (a contract call's
+// structured failure payload, as seen in ScValTypeScvError topics/data of
+// diagnostic events) to its protobuf representation. Every ScError variant
+// carries a single numeric code, just under a different union arm depending
+// on Type, so Code is copied through as-is rather than re-interpreted per
+// variant.
+func convertXdrScErrorToProto(scErr xdr.ScError) *ScError {
+	var code uint32
+	if scErr.Type == xdr.ScErrorTypeSceContract {
+		code = uint32(scErr.MustContractCode())
+	} else {
+		code = uint32(scErr.MustCode())
+	}
+
+	var errType ScErrorType
+	switch scErr.Type {
+	case xdr.ScErrorTypeSceContract:
+		errType = ScErrorType_CONTRACT
+	case xdr.ScErrorTypeSceWasmVm:
+		errType = ScErrorType_WASM_VM
+	case xdr.ScErrorTypeSceContext:
+		errType = ScErrorType_CONTEXT
+	case xdr.ScErrorTypeSceStorage:
+		errType = ScErrorType_STORAGE
+	case xdr.ScErrorTypeSceObject:
+		errType = ScErrorType_OBJECT
+	case xdr.ScErrorTypeSceCrypto:
+		errType = ScErrorType_CRYPTO
+	case xdr.ScErrorTypeSceEvents:
+		errType = ScErrorType_EVENTS
+	case xdr.ScErrorTypeSceBudget:
+		errType = ScErrorType_BUDGET
+	case xdr.ScErrorTypeSceValue:
+		errType = ScErrorType_VALUE
+	case xdr.ScErrorTypeSceAuth:
+		errType = ScErrorType_AUTH
+	default:
+		errType = ScErrorType_CONTRACT
+	}
+
+	return &ScError{Type: errType, Code: code}
+}
+
 // Out returns the output channel for contract events
 func (p *ContractEventsOriginProto) Out() <-chan *ContractEvent {
 	return p.out
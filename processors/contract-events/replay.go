@@ -0,0 +1,55 @@
+package contract_events
+
+import (
+	"context"
+
+	"github.com/stellar/go-stellar-sdk/xdr"
+	"github.com/withObsrvr/nebu-processor-registry/pkg/replay"
+	"github.com/withObsrvr/nebu/pkg/source"
+)
+
+// ReplayOptions configures Replay beyond replay.Options with an optional
+// contract ID allowlist, so a bootstrap/backfill run can target just the
+// contracts an indexer cares about instead of decoding every contract
+// event in the range.
+type ReplayOptions struct {
+	replay.Options
+	// ContractIDs, if non-empty, limits Replay to events from these
+	// contracts. Empty matches every contract.
+	ContractIDs []string
+}
+
+// Replay drives extractLedger over [StartLedger, EndLedger] via
+// pkg/replay's worker pool, reorder buffer, and checkpoint, for indexer
+// bootstraps and gap repair that ContractEventsOriginProto's normal
+// streaming ProcessLedger isn't suited for (it assumes one ledger at a
+// time, in order, from a live source).
+func Replay(ctx context.Context, src source.LedgerSource, networkPassphrase string, opts ReplayOptions) (<-chan *ContractEvent, <-chan error) {
+	origin := NewContractEventsOriginProto(networkPassphrase)
+
+	allow := make(map[string]struct{}, len(opts.ContractIDs))
+	for _, id := range opts.ContractIDs {
+		allow[id] = struct{}{}
+	}
+
+	process := func(ctx context.Context, ledger xdr.LedgerCloseMeta) ([]*ContractEvent, error) {
+		extracted, err := origin.extractLedger(ledger)
+		if err != nil {
+			return nil, err
+		}
+
+		events := make([]*ContractEvent, 0, len(extracted))
+		for _, le := range extracted {
+			if len(allow) > 0 {
+				if _, ok := allow[le.event.ContractId]; !ok {
+					continue
+				}
+			}
+			events = append(events, le.event)
+		}
+		return events, nil
+	}
+
+	runner := replay.NewRunner(src, opts.Options, process)
+	return runner.Run(ctx)
+}
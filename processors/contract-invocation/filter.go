@@ -0,0 +1,257 @@
+package contract_invocation
+
+import (
+	"hash/fnv"
+	"sync"
+
+	"github.com/stellar/go-stellar-sdk/xdr"
+
+	cipb "github.com/withObsrvr/nebu-processor-registry/processors/contract-invocation/proto"
+)
+
+// subscriberBufferSize is the per-subscriber channel depth used by
+// Subscribe. A subscriber that falls behind by more than this many
+// invocations starts losing its oldest buffered ones rather than stalling
+// ledger processing for everyone else.
+const subscriberBufferSize = 256
+
+// FilterQuery selects a subset of contract invocations for a Subscribe
+// channel. A zero-value field disables that criterion rather than matching
+// nothing: an empty FilterQuery matches every invocation.
+type FilterQuery struct {
+	ContractIDs   []string
+	FunctionNames []string
+
+	// TopicMatch is a disjunction of positional patterns: an invocation
+	// matches if any one of its diagnostic events' topics satisfies any
+	// pattern in TopicMatch, mirroring how an Ethereum log filter's
+	// top-level topic groups are OR'd together.
+	TopicMatch [][]TopicPattern
+
+	FromLedger uint32 // 0 means unbounded
+	ToLedger   uint32 // 0 means unbounded
+}
+
+// TopicPattern matches a single, positional entry of a diagnostic event's
+// Event.Body.V0.Topics. It is a disjunction over Values: the topic matches
+// if it equals any one of them. The zero value (no Values) is a wildcard
+// that matches any topic in that position.
+type TopicPattern struct {
+	Values []xdr.ScVal
+}
+
+// CancelFunc stops a Subscribe stream, unregistering it from the
+// dispatcher and closing its channel. Safe to call more than once.
+type CancelFunc func()
+
+// invocationSubscriber is one Subscribe call's registration: its
+// precompiled query and its delivery channel.
+type invocationSubscriber struct {
+	query compiledQuery
+	ch    chan *cipb.ContractInvocation
+}
+
+// Subscribe registers a new, independently-filtered stream of contract
+// invocations alongside Out(), so a consumer that only cares about e.g. one
+// contract's "transfer" calls doesn't have to pull and discard every
+// invocation off the shared channel. query is compiled once here: its
+// TopicMatch values are hashed up front so the per-ledger dispatch in
+// publish only has to hash each ledger topic once, not re-encode every
+// pattern's concrete values on every invocation.
+func (o *Origin) Subscribe(query FilterQuery) (<-chan *cipb.ContractInvocation, CancelFunc) {
+	sub := &invocationSubscriber{
+		query: compileFilterQuery(query),
+		ch:    make(chan *cipb.ContractInvocation, subscriberBufferSize),
+	}
+
+	o.subsMu.Lock()
+	o.subs = append(o.subs, sub)
+	o.subsMu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			o.unsubscribe(sub)
+			close(sub.ch)
+		})
+	}
+
+	return sub.ch, cancel
+}
+
+func (o *Origin) unsubscribe(sub *invocationSubscriber) {
+	o.subsMu.Lock()
+	defer o.subsMu.Unlock()
+	for i, s := range o.subs {
+		if s == sub {
+			o.subs = append(o.subs[:i], o.subs[i+1:]...)
+			return
+		}
+	}
+}
+
+// publish fans a just-built invocation out to every subscriber whose query
+// matches. rawTopicSets holds one raw topic slice per diagnostic event
+// extracted from the same invocation, hashed once here up front and shared
+// across every subscriber's match check.
+func (o *Origin) publish(invocation *cipb.ContractInvocation, rawTopicSets [][]xdr.ScVal) {
+	o.subsMu.RLock()
+	defer o.subsMu.RUnlock()
+
+	if len(o.subs) == 0 {
+		return
+	}
+
+	hashSets := hashTopicSets(rawTopicSets)
+
+	for _, sub := range o.subs {
+		if !sub.query.matches(invocation, hashSets) {
+			continue
+		}
+
+		select {
+		case sub.ch <- invocation:
+		default:
+			// Drop-oldest: evict one buffered invocation and retry once so
+			// a slow subscriber loses history instead of blocking ledger
+			// processing.
+			select {
+			case <-sub.ch:
+			default:
+			}
+			select {
+			case sub.ch <- invocation:
+			default:
+			}
+		}
+	}
+}
+
+// compiledQuery is a FilterQuery with its topic patterns precompiled into
+// hash sets, built once per Subscribe call rather than once per ledger.
+type compiledQuery struct {
+	query      FilterQuery
+	topicMatch [][]compiledTopicPattern
+}
+
+func compileFilterQuery(q FilterQuery) compiledQuery {
+	topicMatch := make([][]compiledTopicPattern, len(q.TopicMatch))
+	for i, pattern := range q.TopicMatch {
+		row := make([]compiledTopicPattern, len(pattern))
+		for j, p := range pattern {
+			row[j] = compileTopicPattern(p)
+		}
+		topicMatch[i] = row
+	}
+	return compiledQuery{query: q, topicMatch: topicMatch}
+}
+
+func (c compiledQuery) matches(invocation *cipb.ContractInvocation, hashSets [][]uint64) bool {
+	q := c.query
+
+	if len(q.ContractIDs) > 0 && !containsString(q.ContractIDs, invocation.ContractId) {
+		return false
+	}
+	if len(q.FunctionNames) > 0 && !containsString(q.FunctionNames, invocation.FunctionName) {
+		return false
+	}
+	if q.FromLedger > 0 && invocation.Meta.LedgerSequence < q.FromLedger {
+		return false
+	}
+	if q.ToLedger > 0 && invocation.Meta.LedgerSequence > q.ToLedger {
+		return false
+	}
+	if len(c.topicMatch) > 0 && !c.anyTopicSetMatches(hashSets) {
+		return false
+	}
+
+	return true
+}
+
+func (c compiledQuery) anyTopicSetMatches(hashSets [][]uint64) bool {
+	for _, hashes := range hashSets {
+		for _, pattern := range c.topicMatch {
+			if patternMatchesHashes(pattern, hashes) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func patternMatchesHashes(pattern []compiledTopicPattern, hashes []uint64) bool {
+	if len(pattern) > len(hashes) {
+		return false
+	}
+	for i, p := range pattern {
+		if !p.matches(hashes[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// compiledTopicPattern is a TopicPattern with its Values precompiled into a
+// hash set, so matching a ledger topic against it is a single map lookup
+// instead of an xdr.ScVal-by-xdr.ScVal comparison.
+type compiledTopicPattern struct {
+	wildcard bool
+	hashes   map[uint64]struct{}
+}
+
+func compileTopicPattern(p TopicPattern) compiledTopicPattern {
+	if len(p.Values) == 0 {
+		return compiledTopicPattern{wildcard: true}
+	}
+
+	hashes := make(map[uint64]struct{}, len(p.Values))
+	for _, v := range p.Values {
+		hashes[hashScVal(v)] = struct{}{}
+	}
+	return compiledTopicPattern{hashes: hashes}
+}
+
+func (c compiledTopicPattern) matches(topicHash uint64) bool {
+	if c.wildcard {
+		return true
+	}
+	_, ok := c.hashes[topicHash]
+	return ok
+}
+
+// hashTopicSets hashes every topic in every set once, so N subscribers'
+// compiledQuery checks against the same invocation never re-encode the
+// same xdr.ScVal more than once.
+func hashTopicSets(topicSets [][]xdr.ScVal) [][]uint64 {
+	hashSets := make([][]uint64, len(topicSets))
+	for i, topics := range topicSets {
+		hashes := make([]uint64, len(topics))
+		for j, topic := range topics {
+			hashes[j] = hashScVal(topic)
+		}
+		hashSets[i] = hashes
+	}
+	return hashSets
+}
+
+// hashScVal returns a content hash of val's XDR encoding, used to compare
+// topic values by hash instead of deep-comparing xdr.ScVal structs on
+// every match check.
+func hashScVal(val xdr.ScVal) uint64 {
+	b, err := val.MarshalBinary()
+	if err != nil {
+		return 0
+	}
+	h := fnv.New64a()
+	h.Write(b)
+	return h.Sum64()
+}
+
+func containsString(haystack []string, needle string) bool {
+	for _, s := range haystack {
+		if s == needle {
+			return true
+		}
+	}
+	return false
+}
@@ -9,8 +9,25 @@ import (
 	"github.com/stellar/go-stellar-sdk/xdr"
 )
 
-// ConvertScValToJSON converts an xdr.ScVal to a JSON-serializable interface
+// ConvertScValToJSONOpts configures ConvertScValToJSONWithOpts.
+type ConvertScValToJSONOpts struct {
+	// OrderedMaps makes ScvMap values encode as an ordered
+	// []interface{} of {"key": ..., "value": ...} entries instead of a
+	// map[string]interface{}, since Soroban maps are ordered and Go map
+	// iteration (and JSON object key order) isn't.
+	OrderedMaps bool
+}
+
+// ConvertScValToJSON converts an xdr.ScVal to a JSON-serializable
+// interface, using map[string]interface{} for ScvMap. Equivalent to
+// ConvertScValToJSONWithOpts with the zero-value options.
 func ConvertScValToJSON(val xdr.ScVal) (interface{}, error) {
+	return ConvertScValToJSONWithOpts(val, ConvertScValToJSONOpts{})
+}
+
+// ConvertScValToJSONWithOpts converts an xdr.ScVal to a JSON-serializable
+// interface.
+func ConvertScValToJSONWithOpts(val xdr.ScVal, opts ConvertScValToJSONOpts) (interface{}, error) {
 	switch val.Type {
 	case xdr.ScValTypeScvBool:
 		return val.MustB(), nil
@@ -79,7 +96,7 @@ func ConvertScValToJSON(val xdr.ScVal) (interface{}, error) {
 		}
 		result := make([]interface{}, len(*vec))
 		for i, item := range *vec {
-			converted, err := ConvertScValToJSON(item)
+			converted, err := ConvertScValToJSONWithOpts(item, opts)
 			if err != nil {
 				return nil, err
 			}
@@ -90,26 +107,40 @@ func ConvertScValToJSON(val xdr.ScVal) (interface{}, error) {
 	case xdr.ScValTypeScvMap:
 		scMap := val.MustMap()
 		if scMap == nil {
+			if opts.OrderedMaps {
+				return []interface{}{}, nil
+			}
 			return map[string]interface{}{}, nil
 		}
+
+		if opts.OrderedMaps {
+			entries := make([]interface{}, len(*scMap))
+			for i, entry := range *scMap {
+				keyConverted, err := ConvertScValToJSONWithOpts(entry.Key, opts)
+				if err != nil {
+					return nil, err
+				}
+				valConverted, err := ConvertScValToJSONWithOpts(entry.Val, opts)
+				if err != nil {
+					return nil, err
+				}
+				entries[i] = map[string]interface{}{"key": keyConverted, "value": valConverted}
+			}
+			return entries, nil
+		}
+
 		result := make(map[string]interface{})
 		for i, entry := range *scMap {
-			keyConverted, err := ConvertScValToJSON(entry.Key)
+			keyConverted, err := ConvertScValToJSONWithOpts(entry.Key, opts)
 			if err != nil {
 				return nil, err
 			}
 
-			// Convert key to string for map
-			keyStr := fmt.Sprintf("%v", keyConverted)
-			if keyConverted == nil {
-				keyStr = fmt.Sprintf("key_%d", i)
-			}
-
-			valConverted, err := ConvertScValToJSON(entry.Val)
+			valConverted, err := ConvertScValToJSONWithOpts(entry.Val, opts)
 			if err != nil {
 				return nil, err
 			}
-			result[keyStr] = valConverted
+			result[scMapKeyString(keyConverted, i)] = valConverted
 		}
 		return result, nil
 
@@ -145,6 +176,9 @@ func ConvertScValToJSON(val xdr.ScVal) (interface{}, error) {
 
 	case xdr.ScValTypeScvDuration:
 		return val.MustDuration(), nil
+
+	case xdr.ScValTypeScvError:
+		return convertScErrorToJSON(val.MustError()), nil
 	}
 
 	return map[string]interface{}{
@@ -153,9 +187,116 @@ func ConvertScValToJSON(val xdr.ScVal) (interface{}, error) {
 	}, nil
 }
 
-// ConvertScValToString converts an xdr.ScVal to a JSON string
+// scMapKeyString renders a converted ScvMap key as a JSON object key.
+// Primitives round-trip losslessly through fmt.Sprintf; anything else
+// (nested maps, vecs) is JSON-serialized instead, so two structurally
+// different keys that happen to share a %v form (e.g. two maps) don't
+// collide on the same string.
+func scMapKeyString(keyConverted interface{}, index int) string {
+	switch k := keyConverted.(type) {
+	case nil:
+		return fmt.Sprintf("key_%d", index)
+	case string, bool, int, int32, int64, uint, uint32, uint64, float32, float64:
+		return fmt.Sprintf("%v", k)
+	default:
+		b, err := json.Marshal(k)
+		if err != nil {
+			return fmt.Sprintf("key_%d", index)
+		}
+		return string(b)
+	}
+}
+
+// convertScErrorToJSON decodes a Soroban xdr.ScError into its canonical
+// (type, code) identity. Type is one of the SCErrorType arms (Contract,
+// WasmVm, Context, Storage, Object, Crypto, Events, Budget, Value, Auth);
+// code is the contract-defined code for Contract errors, or the
+// xdr.ScErrorCode value for every other type.
+func convertScErrorToJSON(scErr xdr.ScError) map[string]interface{} {
+	typeName := scErrorTypeName(scErr.Type)
+
+	var code uint32
+	var codeName string
+	if scErr.Type == xdr.ScErrorTypeSceContract {
+		code = uint32(scErr.MustContractCode())
+		codeName = "ContractError"
+	} else {
+		code = uint32(scErr.MustCode())
+		codeName = scErrorCodeName(scErr.MustCode())
+	}
+
+	return map[string]interface{}{
+		"type":     typeName,
+		"code":     code,
+		"codeName": codeName,
+	}
+}
+
+func scErrorTypeName(t xdr.ScErrorType) string {
+	switch t {
+	case xdr.ScErrorTypeSceContract:
+		return "Contract"
+	case xdr.ScErrorTypeSceWasmVm:
+		return "WasmVm"
+	case xdr.ScErrorTypeSceContext:
+		return "Context"
+	case xdr.ScErrorTypeSceStorage:
+		return "Storage"
+	case xdr.ScErrorTypeSceObject:
+		return "Object"
+	case xdr.ScErrorTypeSceCrypto:
+		return "Crypto"
+	case xdr.ScErrorTypeSceEvents:
+		return "Events"
+	case xdr.ScErrorTypeSceBudget:
+		return "Budget"
+	case xdr.ScErrorTypeSceValue:
+		return "Value"
+	case xdr.ScErrorTypeSceAuth:
+		return "Auth"
+	default:
+		return t.String()
+	}
+}
+
+func scErrorCodeName(c xdr.ScErrorCode) string {
+	switch c {
+	case xdr.ScErrorCodeScecArithDomain:
+		return "ArithDomain"
+	case xdr.ScErrorCodeScecIndexBounds:
+		return "IndexBounds"
+	case xdr.ScErrorCodeScecInvalidInput:
+		return "InvalidInput"
+	case xdr.ScErrorCodeScecMissingValue:
+		return "MissingValue"
+	case xdr.ScErrorCodeScecExistingValue:
+		return "ExistingValue"
+	case xdr.ScErrorCodeScecExceededLimit:
+		return "ExceededLimit"
+	case xdr.ScErrorCodeScecInvalidAction:
+		return "InvalidAction"
+	case xdr.ScErrorCodeScecInternalError:
+		return "InternalError"
+	case xdr.ScErrorCodeScecUnexpectedType:
+		return "UnexpectedType"
+	case xdr.ScErrorCodeScecUnexpectedSize:
+		return "UnexpectedSize"
+	default:
+		return c.String()
+	}
+}
+
+// ConvertScValToString converts an xdr.ScVal to a JSON string using
+// ConvertScValToJSON's default (unordered-map) encoding. Equivalent to
+// ConvertScValToStringWithOpts with the zero-value options.
 func ConvertScValToString(val xdr.ScVal) string {
-	converted, err := ConvertScValToJSON(val)
+	return ConvertScValToStringWithOpts(val, ConvertScValToJSONOpts{})
+}
+
+// ConvertScValToStringWithOpts converts an xdr.ScVal to a JSON string,
+// honoring opts.OrderedMaps for any nested ScvMap values.
+func ConvertScValToStringWithOpts(val xdr.ScVal, opts ConvertScValToJSONOpts) string {
+	converted, err := ConvertScValToJSONWithOpts(val, opts)
 	if err != nil {
 		return fmt.Sprintf("error: %v", err)
 	}
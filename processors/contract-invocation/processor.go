@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"sync"
 	"time"
 
 	"github.com/stellar/go-stellar-sdk/ingest"
@@ -19,6 +20,9 @@ import (
 type Origin struct {
 	passphrase string
 	emitter    *processor.Emitter[*cipb.ContractInvocation]
+
+	subsMu sync.RWMutex
+	subs   []*invocationSubscriber
 }
 
 // NewOrigin creates a new contract invocation origin processor.
@@ -93,12 +97,14 @@ func (o *Origin) ProcessLedger(ctx context.Context, ledger xdr.LedgerCloseMeta)
 		// Check each operation for contract invocations
 		for opIndex, op := range tx.Envelope.Operations() {
 			if op.Body.Type == xdr.OperationTypeInvokeHostFunction {
-				invocation, err := o.processContractInvocation(tx, opIndex, op, sequence, closeTime, txSuccessMap)
+				invocation, rawTopicSets, err := o.processContractInvocation(tx, opIndex, op, sequence, closeTime, txSuccessMap)
 				if err != nil {
 					continue
 				}
 
 				if invocation != nil {
+					o.publish(invocation, rawTopicSets)
+
 					select {
 					case <-ctx.Done():
 						return ctx.Err()
@@ -120,7 +126,7 @@ func (o *Origin) processContractInvocation(
 	sequence uint32,
 	closeTime time.Time,
 	txSuccessMap map[string]bool,
-) (*cipb.ContractInvocation, error) {
+) (*cipb.ContractInvocation, [][]xdr.ScVal, error) {
 	invokeHostFunction := op.Body.MustInvokeHostFunctionOp()
 
 	// Get the invoking account
@@ -138,7 +144,7 @@ func (o *Origin) processContractInvocation(
 		var err error
 		contractID, err = strkey.Encode(strkey.VersionByteContract, contractIDBytes[:])
 		if err != nil {
-			return nil, fmt.Errorf("error encoding contract ID: %w", err)
+			return nil, nil, fmt.Errorf("error encoding contract ID: %w", err)
 		}
 	}
 
@@ -186,8 +192,11 @@ func (o *Origin) processContractInvocation(
 		}
 	}
 
-	// Extract diagnostic events
-	invocation.DiagnosticEvents = o.extractDiagnosticEvents(tx)
+	// Extract diagnostic events, keeping each event's raw topics alongside
+	// so Subscribe's topic matchers can compare against xdr.ScVal values
+	// instead of the already-stringified DiagnosticEvent.Topics.
+	diagEvents, rawTopicSets := o.extractDiagnosticEvents(tx)
+	invocation.DiagnosticEvents = diagEvents
 
 	// Extract contract calls
 	invocation.ContractCalls = o.extractContractCalls(tx, opIndex, invokeHostFunction, contractID)
@@ -195,19 +204,24 @@ func (o *Origin) processContractInvocation(
 	// Extract state changes
 	invocation.StateChanges = o.extractStateChanges(tx)
 
-	// Extract TTL extensions (placeholder for now)
+	// Extract TTL extensions
 	invocation.TtlExtensions = o.extractTtlExtensions(tx)
 
-	return invocation, nil
+	return invocation, rawTopicSets, nil
 }
 
-func (o *Origin) extractDiagnosticEvents(tx ingest.LedgerTransaction) []*cipb.DiagnosticEvent {
+// extractDiagnosticEvents returns each diagnostic event's proto form
+// alongside its raw, undecoded topics, index-for-index, so a caller that
+// needs to run xdr.ScVal-based matching (Subscribe's topic patterns) isn't
+// limited to the already-stringified DiagnosticEvent.Topics.
+func (o *Origin) extractDiagnosticEvents(tx ingest.LedgerTransaction) ([]*cipb.DiagnosticEvent, [][]xdr.ScVal) {
 	var events []*cipb.DiagnosticEvent
+	var rawTopicSets [][]xdr.ScVal
 
 	// Check if we have diagnostic events in the transaction meta
 	diagnosticEvents, err := tx.GetDiagnosticEvents()
 	if err != nil || len(diagnosticEvents) == 0 {
-		return events
+		return events, rawTopicSets
 	}
 
 	for _, diagEvent := range diagnosticEvents {
@@ -223,9 +237,11 @@ func (o *Origin) extractDiagnosticEvents(tx ingest.LedgerTransaction) []*cipb.Di
 
 		// Decode topics
 		var topics []string
+		var rawTopics []xdr.ScVal
 		if diagEvent.Event.Body.V == 0 && diagEvent.Event.Body.V0 != nil {
 			for _, topic := range diagEvent.Event.Body.V0.Topics {
 				topics = append(topics, ConvertScValToString(topic))
+				rawTopics = append(rawTopics, topic)
 			}
 		}
 
@@ -242,9 +258,10 @@ func (o *Origin) extractDiagnosticEvents(tx ingest.LedgerTransaction) []*cipb.Di
 			InSuccessfulCall: diagEvent.InSuccessfulContractCall,
 			EventType:        uint32(diagEvent.Event.Type),
 		})
+		rawTopicSets = append(rawTopicSets, rawTopics)
 	}
 
-	return events
+	return events, rawTopicSets
 }
 
 func (o *Origin) extractContractCalls(
@@ -431,9 +448,3 @@ func (o *Origin) extractStateChangeFromContractData(
 		Operation:  operation,
 	}
 }
-
-func (o *Origin) extractTtlExtensions(tx ingest.LedgerTransaction) []*cipb.TtlExtension {
-	// TTL extensions are not currently extracted in this simplified version
-	// This is a placeholder for future implementation
-	return nil
-}
@@ -0,0 +1,204 @@
+package contract_invocation
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/stellar/go-stellar-sdk/ingest"
+	"github.com/stellar/go-stellar-sdk/strkey"
+	"github.com/stellar/go-stellar-sdk/xdr"
+
+	cipb "github.com/withObsrvr/nebu-processor-registry/processors/contract-invocation/proto"
+)
+
+// ttlOwner identifies the contract-data or contract-code entry a TTL
+// ledger entry governs.
+type ttlOwner struct {
+	contractID string
+	entryType  string
+}
+
+// extractTtlExtensions walks tx's ledger entry changes for
+// LedgerEntryTypeTtl entries and turns each live-until increase into a
+// *cipb.TtlExtension, resolving the TTL entry's KeyHash back to the
+// contract-data/contract-code entry it governs by recomputing that hash
+// for every such entry the same transaction touched.
+//
+// Source is tagged "op" for every TTL increase in a transaction whose
+// operations include ExtendFootprintTtlOp or RestoreFootprintOp (the two
+// operation types that explicitly request a TTL bump), and "auto"
+// otherwise — an increase observed only in meta, e.g. a bump applied as a
+// side effect of invoking the contract. This is tx-level rather than
+// per-key attribution: the operations' footprint (which keys they target)
+// is carried in the transaction's Soroban resource data, not the
+// operation itself, so a single tx-wide label is what's reliably
+// derivable here.
+func (o *Origin) extractTtlExtensions(tx ingest.LedgerTransaction) []*cipb.TtlExtension {
+	txChanges, err := tx.GetChanges()
+	if err != nil {
+		return nil
+	}
+
+	owners := ttlKeyOwners(tx, txChanges)
+	source := ttlSource(tx)
+
+	var extensions []*cipb.TtlExtension
+	for _, change := range txChanges {
+		if change.Type != xdr.LedgerEntryTypeTtl {
+			continue
+		}
+
+		var oldLive, newLive uint32
+		var keyHash xdr.Hash
+
+		switch change.ChangeType {
+		case xdr.LedgerEntryChangeTypeLedgerEntryUpdated:
+			if change.Pre == nil || change.Post == nil ||
+				change.Pre.Data.Ttl == nil || change.Post.Data.Ttl == nil {
+				continue
+			}
+			oldLive = uint32(change.Pre.Data.Ttl.LiveUntilLedgerSeq)
+			newLive = uint32(change.Post.Data.Ttl.LiveUntilLedgerSeq)
+			keyHash = change.Post.Data.Ttl.KeyHash
+
+		case xdr.LedgerEntryChangeTypeLedgerEntryCreated:
+			if change.Post == nil || change.Post.Data.Ttl == nil {
+				continue
+			}
+			newLive = uint32(change.Post.Data.Ttl.LiveUntilLedgerSeq)
+			keyHash = change.Post.Data.Ttl.KeyHash
+
+		default:
+			continue // removals have no new live-until to report
+		}
+
+		if newLive <= oldLive {
+			continue // not an extension
+		}
+
+		owner := owners[keyHash]
+		extensions = append(extensions, &cipb.TtlExtension{
+			ContractId:         owner.contractID,
+			KeyHash:            hex.EncodeToString(keyHash[:]),
+			EntryType:          owner.entryType,
+			OldLiveUntilLedger: oldLive,
+			NewLiveUntilLedger: newLive,
+			ExtendedBy:         newLive - oldLive,
+			Source:             source,
+		})
+	}
+
+	return extensions
+}
+
+// ttlSource reports "op" if tx contains an operation that explicitly
+// requests a TTL bump, "auto" otherwise.
+func ttlSource(tx ingest.LedgerTransaction) string {
+	for _, op := range tx.Envelope.Operations() {
+		switch op.Body.Type {
+		case xdr.OperationTypeExtendFootprintTtl, xdr.OperationTypeRestoreFootprint:
+			return "op"
+		}
+	}
+	return "auto"
+}
+
+// ttlKeyOwners indexes every contract-data/contract-code key this
+// transaction touches by the TTL key hash that governs it, so a
+// TtlEntry.KeyHash can be resolved back to its owning entry. Two sources
+// are consulted: changes' pre-/post-images (covers entries whose content
+// also changed, e.g. a contract invocation that wrote new state), and the
+// transaction's Soroban footprint (covers a bare ExtendFootprintTtlOp or
+// RestoreFootprintOp, which typically produces a ledger-entry change only
+// for the TTL entry itself — the underlying data/code entry's content is
+// untouched, so it never appears in changes at all).
+func ttlKeyOwners(tx ingest.LedgerTransaction, changes []ingest.Change) map[xdr.Hash]ttlOwner {
+	owners := make(map[xdr.Hash]ttlOwner)
+
+	recordKey := func(key xdr.LedgerKey) {
+		switch key.Type {
+		case xdr.LedgerEntryTypeContractData:
+			cd := key.ContractData
+			if cd == nil || cd.Contract.ContractId == nil {
+				return
+			}
+			hash, err := ledgerKeyHash(key)
+			if err != nil {
+				return
+			}
+			contractID, err := strkey.Encode(strkey.VersionByteContract, cd.Contract.ContractId[:])
+			if err != nil {
+				return
+			}
+			owners[hash] = ttlOwner{contractID: contractID, entryType: "ContractData"}
+
+		case xdr.LedgerEntryTypeContractCode:
+			if key.ContractCode == nil {
+				return
+			}
+			hash, err := ledgerKeyHash(key)
+			if err != nil {
+				return
+			}
+			owners[hash] = ttlOwner{entryType: "ContractCode"}
+		}
+	}
+
+	record := func(entry *xdr.LedgerEntry) {
+		if entry == nil {
+			return
+		}
+
+		switch entry.Data.Type {
+		case xdr.LedgerEntryTypeContractData:
+			cd := entry.Data.ContractData
+			if cd == nil {
+				return
+			}
+			recordKey(xdr.LedgerKey{
+				Type: xdr.LedgerEntryTypeContractData,
+				ContractData: &xdr.LedgerKeyContractData{
+					Contract:   cd.Contract,
+					Key:        cd.Key,
+					Durability: cd.Durability,
+				},
+			})
+
+		case xdr.LedgerEntryTypeContractCode:
+			cc := entry.Data.ContractCode
+			if cc == nil {
+				return
+			}
+			recordKey(xdr.LedgerKey{
+				Type:         xdr.LedgerEntryTypeContractCode,
+				ContractCode: &xdr.LedgerKeyContractCode{Hash: cc.Hash},
+			})
+		}
+	}
+
+	for _, change := range changes {
+		record(change.Pre)
+		record(change.Post)
+	}
+
+	if sorobanData, ok := tx.GetSorobanData(); ok {
+		for _, key := range sorobanData.Resources.Footprint.ReadOnly {
+			recordKey(key)
+		}
+		for _, key := range sorobanData.Resources.Footprint.ReadWrite {
+			recordKey(key)
+		}
+	}
+
+	return owners
+}
+
+// ledgerKeyHash computes the SHA-256 hash of key's XDR encoding, the same
+// key used to look up a LedgerEntryTypeTtl entry's TTL (CAP-0046).
+func ledgerKeyHash(key xdr.LedgerKey) (xdr.Hash, error) {
+	b, err := key.MarshalBinary()
+	if err != nil {
+		return xdr.Hash{}, err
+	}
+	return sha256.Sum256(b), nil
+}
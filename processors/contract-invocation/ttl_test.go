@@ -0,0 +1,65 @@
+package contract_invocation
+
+import (
+	"testing"
+
+	"github.com/stellar/go-stellar-sdk/ingest"
+	"github.com/stellar/go-stellar-sdk/strkey"
+	"github.com/stellar/go-stellar-sdk/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTtlKeyOwners_ResolvesFromFootprint_WithoutDataChange covers a bare
+// ExtendFootprintTtlOp: the contract-data entry it extends never appears in
+// txChanges (only the TTL entry itself does, since the data isn't
+// modified), so owner resolution must fall back to the transaction's
+// Soroban footprint instead of coming back empty.
+func TestTtlKeyOwners_ResolvesFromFootprint_WithoutDataChange(t *testing.T) {
+	var contractID xdr.ContractId
+	contractID[0] = 0xAA
+
+	dataKey := xdr.LedgerKey{
+		Type: xdr.LedgerEntryTypeContractData,
+		ContractData: &xdr.LedgerKeyContractData{
+			Contract:   xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeContract, ContractId: &contractID},
+			Key:        xdr.ScVal{Type: xdr.ScValTypeScvLedgerKeyContractInstance},
+			Durability: xdr.ContractDataDurabilityPersistent,
+		},
+	}
+	dataKeyHash, err := ledgerKeyHash(dataKey)
+	require.NoError(t, err)
+
+	tx := ingest.LedgerTransaction{
+		Envelope: xdr.TransactionEnvelope{
+			Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+			V1: &xdr.TransactionV1Envelope{
+				Tx: xdr.Transaction{
+					Ext: xdr.TransactionExt{
+						V: 1,
+						SorobanData: &xdr.SorobanTransactionData{
+							Resources: xdr.SorobanResources{
+								Footprint: xdr.LedgerFootprint{
+									ReadWrite: []xdr.LedgerKey{dataKey},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// No txChanges at all for the contract-data entry: only the TTL entry
+	// itself would show up in a real ledger, and that's not part of
+	// ttlKeyOwners' input.
+	owners := ttlKeyOwners(tx, nil)
+
+	owner, ok := owners[dataKeyHash]
+	require.True(t, ok, "owner should resolve from the Soroban footprint even with no matching ledger-entry change")
+	assert.Equal(t, "ContractData", owner.entryType)
+
+	wantContractID, err := strkey.Encode(strkey.VersionByteContract, contractID[:])
+	require.NoError(t, err)
+	assert.Equal(t, wantContractID, owner.contractID)
+}
@@ -0,0 +1,163 @@
+package dedup
+
+import (
+	"hash/fnv"
+	"math"
+)
+
+// Scalable Bloom Filters (Almeida et al., 2007): each new layer doubles
+// capacity and tightens its false-positive target by tighteningRatio, so
+// the filter keeps accepting new keys indefinitely instead of a
+// fixed-capacity Bloom filter's false-positive rate degrading once it
+// fills up.
+const (
+	growthFactor    = 2
+	tighteningRatio = 0.9
+	fillThreshold   = 0.5
+)
+
+// bitBloom is a classic fixed-size Bloom filter. It uses Kirsch-Mitzenmacher
+// double hashing (two real hashes combined to derive k probe positions)
+// instead of computing k independent hash functions per add/test.
+type bitBloom struct {
+	bits    []uint64
+	m       uint64 // number of bits
+	k       int    // number of hash probes
+	n       uint64 // items added
+	setBits uint64 // bits currently set, maintained incrementally by add
+}
+
+func newBitBloom(capacity uint64, fpp float64) *bitBloom {
+	m := optimalM(capacity, fpp)
+	k := optimalK(m, capacity)
+	return &bitBloom{bits: make([]uint64, (m+63)/64), m: m, k: k}
+}
+
+func optimalM(n uint64, p float64) uint64 {
+	if n == 0 {
+		n = 1
+	}
+	m := math.Ceil(-1 * float64(n) * math.Log(p) / (math.Ln2 * math.Ln2))
+	if m < 64 {
+		m = 64
+	}
+	return uint64(m)
+}
+
+func optimalK(m, n uint64) int {
+	if n == 0 {
+		return 1
+	}
+	k := int(math.Round(float64(m) / float64(n) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return k
+}
+
+func (b *bitBloom) positions(data []byte) []uint64 {
+	h1 := fnv.New64a()
+	h1.Write(data)
+	sum1 := h1.Sum64()
+
+	h2 := fnv.New64()
+	h2.Write(data)
+	sum2 := h2.Sum64()
+
+	positions := make([]uint64, b.k)
+	for i := 0; i < b.k; i++ {
+		positions[i] = (sum1 + uint64(i)*sum2) % b.m
+	}
+	return positions
+}
+
+func (b *bitBloom) test(data []byte) bool {
+	for _, pos := range b.positions(data) {
+		if b.bits[pos/64]&(1<<(pos%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *bitBloom) add(data []byte) {
+	for _, pos := range b.positions(data) {
+		word, mask := pos/64, uint64(1)<<(pos%64)
+		if b.bits[word]&mask == 0 {
+			b.bits[word] |= mask
+			b.setBits++
+		}
+	}
+	b.n++
+}
+
+// fillRatio estimates the fraction of bits set, used to decide when this
+// layer is full enough that a scalableBloom should start a new one. It
+// reads the running setBits counter add maintains rather than rescanning
+// the whole bit array — with TestAndAdd calling this on every unseen key,
+// a bits.OnesCount64 scan per insert would make whole-stream cost O(n²)
+// at realistic filter sizes (the CLI's own --bloom-capacity 100000000
+// example is a multi-million-word array).
+func (b *bitBloom) fillRatio() float64 {
+	return float64(b.setBits) / float64(b.m)
+}
+
+// estimatedFPP approximates this layer's current false-positive rate from
+// its fill ratio: (fraction of bits set)^k.
+func (b *bitBloom) estimatedFPP() float64 {
+	return math.Pow(b.fillRatio(), float64(b.k))
+}
+
+// scalableBloom is a growable Bloom filter: a chain of bitBloom layers,
+// each larger and tighter than the last, so membership testing stays
+// useful for long-running streams instead of a single layer's
+// false-positive rate climbing once it's full.
+type scalableBloom struct {
+	layers       []*bitBloom
+	baseCapacity uint64
+	baseFPP      float64
+}
+
+func newScalableBloom(capacity uint64, fpp float64) *scalableBloom {
+	sb := &scalableBloom{baseCapacity: capacity, baseFPP: fpp}
+	sb.addLayer()
+	return sb
+}
+
+func (sb *scalableBloom) addLayer() {
+	capacity, layerFPP := sb.baseCapacity, sb.baseFPP
+	for i := 0; i < len(sb.layers); i++ {
+		capacity *= growthFactor
+		layerFPP *= tighteningRatio
+	}
+	sb.layers = append(sb.layers, newBitBloom(capacity, layerFPP))
+}
+
+// TestAndAdd reports whether data was (probably) already present in any
+// layer. If it wasn't, it's added to the newest layer, growing a fresh
+// layer first if the newest one is past fillThreshold.
+func (sb *scalableBloom) TestAndAdd(data []byte) bool {
+	for _, layer := range sb.layers {
+		if layer.test(data) {
+			return true
+		}
+	}
+
+	newest := sb.layers[len(sb.layers)-1]
+	if newest.fillRatio() > fillThreshold {
+		sb.addLayer()
+		newest = sb.layers[len(sb.layers)-1]
+	}
+	newest.add(data)
+	return false
+}
+
+// EstimatedFPP approximates the compound false-positive rate across every
+// layer: 1 - the probability that none of them false-positive.
+func (sb *scalableBloom) EstimatedFPP() float64 {
+	survive := 1.0
+	for _, layer := range sb.layers {
+		survive *= 1 - layer.estimatedFPP()
+	}
+	return 1 - survive
+}
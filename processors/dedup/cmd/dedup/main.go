@@ -1,7 +1,10 @@
 // Package main provides a standalone CLI for the dedup transform processor.
 //
-// This processor removes duplicate events based on specified keys.
-// It reads JSON events from stdin and writes unique events to stdout.
+// This processor removes duplicate events based on specified keys. It
+// reads JSON events from stdin and writes unique events to stdout, using a
+// bounded two-tier cache (processors/dedup.Deduplicator) instead of an
+// unbounded map, so long-running pipelines (millions of ledgers piped
+// through token-transfer | dedup) don't grow memory without limit.
 //
 // Usage:
 //
@@ -11,26 +14,40 @@
 //	# Deduplicate by multiple fields
 //	cat events.jsonl | dedup --key meta.txHash,meta.ledgerSequence
 //
-//	# Remove duplicate transfers in pipeline
+//	# Bound memory with an LRU plus a Bloom filter for long-tail dupes,
+//	# expiring exact tracking after 24h, and resume state across restarts
 //	token-transfer --start-ledger 60200000 --end-ledger 60200100 | \
-//	  dedup --key meta.txHash | \
+//	  dedup --key meta.txHash --cache-size 1000000 \
+//	    --bloom-fpp 0.01 --bloom-capacity 100000000 \
+//	    --window 24h --persist dedup.state | \
 //	  json-file-sink --out unique-transfers.jsonl
 package main
 
 import (
 	"fmt"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/withObsrvr/nebu-processor-registry/processors/dedup"
 	"github.com/withObsrvr/nebu/pkg/processor/cli"
 )
 
-var version = "0.1.0"
+var version = "0.2.0"
 
-var dedupKeys string
+var (
+	dedupKeys string
 
-// Track seen keys
-var seenKeys = make(map[string]bool)
+	cacheSize     int
+	bloomFPP      float64
+	bloomCapacity uint64
+	window        string
+	persistPath   string
+
+	deduplicator *dedup.Deduplicator
+	stop         = make(chan struct{})
+)
 
 func main() {
 	config := cli.TransformConfig{
@@ -40,10 +57,59 @@ func main() {
 	}
 
 	cli.RunTransformCLI(config, deduplicate, addFlags)
+
+	close(stop)
+	if deduplicator != nil {
+		if err := deduplicator.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "dedup: saving snapshot: %v\n", err)
+		}
+	}
 }
 
 func addFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&dedupKeys, "key", "meta.txHash", "Comma-separated list of keys to use for deduplication (supports dot notation, e.g., meta.txHash or meta.txHash,meta.ledgerSequence)")
+	cmd.Flags().IntVar(&cacheSize, "cache-size", 1_000_000, "Exact-match LRU capacity (number of recent keys tracked)")
+	cmd.Flags().Float64Var(&bloomFPP, "bloom-fpp", 0, "Target false-positive rate for the long-tail Bloom filter tier (0 disables it)")
+	cmd.Flags().Uint64Var(&bloomCapacity, "bloom-capacity", 10_000_000, "Expected item count the Bloom filter's first layer is sized for")
+	cmd.Flags().StringVar(&window, "window", "", "Expire LRU entries after this many events (e.g. 500000) or this long (e.g. 24h); empty means entries only expire via --cache-size eviction")
+	cmd.Flags().StringVar(&persistPath, "persist", "", "File to snapshot dedup cache state to on shutdown and reload from on startup")
+}
+
+func newDeduplicator() (*dedup.Deduplicator, error) {
+	opts := dedup.Options{
+		CacheSize:     cacheSize,
+		BloomFPP:      bloomFPP,
+		BloomCapacity: bloomCapacity,
+		PersistPath:   persistPath,
+	}
+
+	if window != "" {
+		if n, err := parseEventCount(window); err == nil {
+			opts.WindowEvents = n
+		} else if d, err := time.ParseDuration(window); err == nil {
+			opts.Window = d
+		} else {
+			return nil, fmt.Errorf("invalid --window %q: not an event count or a duration", window)
+		}
+	}
+
+	d, err := dedup.NewDeduplicator(opts)
+	if err != nil {
+		return nil, err
+	}
+	d.ReportStatsOnSIGUSR1(os.Stderr, stop)
+	return d, nil
+}
+
+func parseEventCount(s string) (uint64, error) {
+	var n uint64
+	if _, err := fmt.Sscanf(s, "%d", &n); err != nil {
+		return 0, err
+	}
+	if fmt.Sprintf("%d", n) != s {
+		return 0, fmt.Errorf("not a plain integer")
+	}
+	return n, nil
 }
 
 // getNestedValue retrieves a value from a nested map using dot notation.
@@ -75,6 +141,15 @@ func getNestedValue(event map[string]interface{}, key string) (interface{}, bool
 // deduplicate removes duplicate events based on the specified keys.
 // Returns the event if it's unique, nil if it's a duplicate.
 func deduplicate(event map[string]interface{}) map[string]interface{} {
+	if deduplicator == nil {
+		d, err := newDeduplicator()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "dedup: %v\n", err)
+			return event
+		}
+		deduplicator = d
+	}
+
 	// Parse keys
 	keys := strings.Split(dedupKeys, ",")
 
@@ -93,12 +168,9 @@ func deduplicate(event map[string]interface{}) map[string]interface{} {
 	// Create composite key
 	compositeKey := strings.Join(keyParts, "|")
 
-	// Check if we've seen this key before
-	if seenKeys[compositeKey] {
+	if deduplicator.Seen(compositeKey) {
 		return nil // Duplicate, filter out
 	}
 
-	// Mark as seen and pass through
-	seenKeys[compositeKey] = true
 	return event
 }
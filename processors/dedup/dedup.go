@@ -0,0 +1,170 @@
+// Package dedup provides a bounded, two-tier duplicate-key filter for
+// long-running event streams.
+package dedup
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Options configures a Deduplicator.
+type Options struct {
+	// CacheSize is the LRU tier's capacity: the number of recently-seen
+	// keys tracked for exact dedup. 0 disables the LRU tier.
+	CacheSize int
+
+	// BloomFPP and BloomCapacity size the Bloom tier used for approximate
+	// long-tail membership once a key ages out of the LRU. BloomFPP is
+	// the target false-positive rate at BloomCapacity items; the filter
+	// grows (see scalableBloom) past that without the rate degrading the
+	// way a fixed-size Bloom filter's would. BloomFPP <= 0 disables the
+	// Bloom tier entirely, leaving dedup bounded but exact-recent-window
+	// only.
+	BloomFPP      float64
+	BloomCapacity uint64
+
+	// Window and WindowEvents bound how long a key counts as "seen" in
+	// the LRU tier: Window by wall-clock age, WindowEvents by how many
+	// Seen calls have happened since. Either, both, or neither may be
+	// set; 0 means that dimension doesn't expire keys. Note this only
+	// affects the LRU tier — the Bloom tier, once it has seen a key,
+	// never forgets it, by design, to catch long-tail duplicates that
+	// fell out of the LRU.
+	Window       time.Duration
+	WindowEvents uint64
+
+	// PersistPath, if set, is loaded on NewDeduplicator and written by
+	// Close, so a pipeline can be restarted without re-admitting keys it
+	// already deduplicated.
+	PersistPath string
+}
+
+// Stats reports a Deduplicator's cache behavior.
+type Stats struct {
+	Hits         uint64
+	Misses       uint64
+	Evictions    uint64
+	EstimatedFPP float64
+}
+
+// Deduplicator is a bounded "have I seen this key" cache: an exact LRU for
+// the recent window, backed by an optional scalable Bloom filter for
+// approximate long-tail membership once a key ages out of the LRU. This
+// keeps memory bounded for long-running streams (millions of ledgers piped
+// through token-transfer | dedup) where an unbounded map[string]bool would
+// grow without limit.
+type Deduplicator struct {
+	mu    sync.Mutex
+	opts  Options
+	lru   *lru
+	bloom *scalableBloom
+	seq   uint64
+	stats Stats
+}
+
+// NewDeduplicator creates a Deduplicator, loading opts.PersistPath's
+// snapshot first if it's set and exists.
+func NewDeduplicator(opts Options) (*Deduplicator, error) {
+	d := &Deduplicator{opts: opts, lru: newLRU(opts.CacheSize)}
+	if opts.BloomFPP > 0 && opts.BloomCapacity > 0 {
+		d.bloom = newScalableBloom(opts.BloomCapacity, opts.BloomFPP)
+	}
+
+	if opts.PersistPath != "" {
+		if err := d.load(opts.PersistPath); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("loading dedup snapshot %s: %w", opts.PersistPath, err)
+		}
+	}
+
+	return d, nil
+}
+
+// Seen reports whether key has already been observed within the
+// configured window, recording it as seen either way.
+func (d *Deduplicator) Seen(key string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.seq++
+	seq, now := d.seq, time.Now()
+
+	if entry, ok := d.lru.get(key); ok && d.withinWindow(entry, now) {
+		d.stats.Hits++
+		d.lru.put(key, entry.seq, entry.addedAt) // refresh recency, keep its original window start
+		return true
+	}
+
+	if d.bloom != nil && d.bloom.TestAndAdd([]byte(key)) {
+		d.stats.Hits++
+		d.lru.put(key, seq, now)
+		return true
+	}
+
+	d.lru.put(key, seq, now)
+	d.stats.Misses++
+	return false
+}
+
+func (d *Deduplicator) withinWindow(entry *lruEntry, now time.Time) bool {
+	if d.opts.Window > 0 && now.Sub(entry.addedAt) > d.opts.Window {
+		return false
+	}
+	if d.opts.WindowEvents > 0 && d.seq-entry.seq > d.opts.WindowEvents {
+		return false
+	}
+	return true
+}
+
+// Stats returns a snapshot of the current cache statistics.
+func (d *Deduplicator) Stats() Stats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	stats := d.stats
+	stats.Evictions = d.lru.evictions
+	if d.bloom != nil {
+		stats.EstimatedFPP = d.bloom.EstimatedFPP()
+	}
+	return stats
+}
+
+// ReportStatsOnSIGUSR1 logs Stats to w every time the process receives
+// SIGUSR1, so an operator can check a long-running dedup's cache behavior
+// without stopping it. It runs until stop is closed, mirroring
+// pkg/filter.List.ReloadOnSIGHUP's signal-to-method wiring.
+func (d *Deduplicator) ReportStatsOnSIGUSR1(w *os.File, stop <-chan struct{}) {
+	sigusr1 := make(chan os.Signal, 1)
+	signal.Notify(sigusr1, syscall.SIGUSR1)
+	go func() {
+		defer signal.Stop(sigusr1)
+		for {
+			select {
+			case <-stop:
+				return
+			case <-sigusr1:
+				d.logStats(w)
+			}
+		}
+	}()
+}
+
+func (d *Deduplicator) logStats(w *os.File) {
+	s := d.Stats()
+	fmt.Fprintf(w, "dedup: hits=%d misses=%d evictions=%d estimated_fpp=%.4f\n",
+		s.Hits, s.Misses, s.Evictions, s.EstimatedFPP)
+}
+
+// Close saves a snapshot to opts.PersistPath if configured and reports
+// final stats to stderr.
+func (d *Deduplicator) Close() error {
+	d.logStats(os.Stderr)
+
+	if d.opts.PersistPath == "" {
+		return nil
+	}
+	return d.save(d.opts.PersistPath)
+}
@@ -0,0 +1,90 @@
+package dedup
+
+import (
+	"container/list"
+	"time"
+)
+
+// lruEntry is one cached key plus the bookkeeping a Deduplicator's --window
+// expiry needs: when the key was first seen (addedAt) and how many events
+// had passed at that point (seq).
+type lruEntry struct {
+	key     string
+	seq     uint64
+	addedAt time.Time
+}
+
+// lru is a fixed-capacity, most-recently-used cache of keys. Unlike a
+// plain map[string]bool, it never grows past capacity: once full, the
+// least-recently-touched key is evicted to make room for a new one.
+type lru struct {
+	capacity  int
+	ll        *list.List
+	items     map[string]*list.Element
+	evictions uint64
+}
+
+// newLRU creates an lru bounded to capacity entries. A capacity of 0
+// disables the LRU tier entirely: get always misses and put is a no-op.
+func newLRU(capacity int) *lru {
+	return &lru{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// get returns key's entry without changing its recency, so a window check
+// can be made before deciding whether this counts as a touch.
+func (c *lru) get(key string) (*lruEntry, bool) {
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	return el.Value.(*lruEntry), true
+}
+
+// put inserts or refreshes key as the most-recently-used entry with the
+// given seq/addedAt, evicting the oldest entry if this pushes the cache
+// over capacity.
+func (c *lru) put(key string, seq uint64, addedAt time.Time) {
+	if c.capacity <= 0 {
+		return
+	}
+
+	if el, ok := c.items[key]; ok {
+		el.Value.(*lruEntry).seq = seq
+		el.Value.(*lruEntry).addedAt = addedAt
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	el := c.ll.PushFront(&lruEntry{key: key, seq: seq, addedAt: addedAt})
+	c.items[key] = el
+
+	if c.ll.Len() > c.capacity {
+		c.evictOldest()
+	}
+}
+
+func (c *lru) evictOldest() {
+	el := c.ll.Back()
+	if el == nil {
+		return
+	}
+	c.ll.Remove(el)
+	delete(c.items, el.Value.(*lruEntry).key)
+	c.evictions++
+}
+
+func (c *lru) len() int { return c.ll.Len() }
+
+// entries returns every cached entry, oldest first, for persistence
+// snapshots.
+func (c *lru) entries() []*lruEntry {
+	entries := make([]*lruEntry, 0, c.ll.Len())
+	for el := c.ll.Back(); el != nil; el = el.Prev() {
+		entries = append(entries, el.Value.(*lruEntry))
+	}
+	return entries
+}
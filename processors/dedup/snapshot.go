@@ -0,0 +1,105 @@
+package dedup
+
+import (
+	"encoding/gob"
+	"math/bits"
+	"os"
+	"time"
+)
+
+func timeFromUnixNano(nano int64) time.Time {
+	return time.Unix(0, nano)
+}
+
+// snapshot is the on-disk form a --persist file stores, so a restarted
+// pipeline resumes with the same LRU contents, Bloom filter state, and
+// event sequence instead of starting cold.
+type snapshot struct {
+	Seq         uint64
+	Keys        []keySnapshot
+	BloomLayers []bloomLayerSnapshot
+}
+
+type keySnapshot struct {
+	Key             string
+	Seq             uint64
+	AddedAtUnixNano int64
+}
+
+type bloomLayerSnapshot struct {
+	Bits []uint64
+	M    uint64
+	K    int
+	N    uint64
+}
+
+func (d *Deduplicator) save(path string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	snap := snapshot{Seq: d.seq}
+
+	for _, e := range d.lru.entries() {
+		snap.Keys = append(snap.Keys, keySnapshot{
+			Key:             e.key,
+			Seq:             e.seq,
+			AddedAtUnixNano: e.addedAt.UnixNano(),
+		})
+	}
+
+	if d.bloom != nil {
+		for _, layer := range d.bloom.layers {
+			snap.BloomLayers = append(snap.BloomLayers, bloomLayerSnapshot{
+				Bits: layer.bits,
+				M:    layer.m,
+				K:    layer.k,
+				N:    layer.n,
+			})
+		}
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return gob.NewEncoder(f).Encode(snap)
+}
+
+func (d *Deduplicator) load(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var snap snapshot
+	if err := gob.NewDecoder(f).Decode(&snap); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.seq = snap.Seq
+	for _, k := range snap.Keys {
+		d.lru.put(k.Key, k.Seq, timeFromUnixNano(k.AddedAtUnixNano))
+	}
+
+	if len(snap.BloomLayers) > 0 {
+		if d.bloom == nil {
+			d.bloom = &scalableBloom{baseCapacity: d.opts.BloomCapacity, baseFPP: d.opts.BloomFPP}
+		}
+		d.bloom.layers = d.bloom.layers[:0]
+		for _, ls := range snap.BloomLayers {
+			layer := &bitBloom{bits: ls.Bits, m: ls.M, k: ls.K, n: ls.N}
+			for _, word := range ls.Bits {
+				layer.setBits += uint64(bits.OnesCount64(word))
+			}
+			d.bloom.layers = append(d.bloom.layers, layer)
+		}
+	}
+
+	return nil
+}
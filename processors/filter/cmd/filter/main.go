@@ -0,0 +1,75 @@
+// Package main provides a standalone CLI for the generic filter transform
+// processor.
+//
+// Unlike usdc-filter or amount-filter, which hard-code one condition in Go,
+// this processor evaluates a small expression language at runtime so
+// pipelines can filter on arbitrary fields without a custom binary.
+//
+// Usage:
+//
+//	# Large USDC transfers only
+//	token-transfer --start-ledger 60200000 --end-ledger 60200100 | \
+//	  filter --where 'type=="transfer" && amount>1000 && asset.code=="USDC"'
+//
+//	# Combine multiple --where flags (ANDed together)
+//	cat events.jsonl | filter --where 'type=="transfer"' --where 'amount>0'
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/withObsrvr/nebu-processor-registry/pkg/filter"
+	"github.com/withObsrvr/nebu/pkg/processor/cli"
+)
+
+var version = "0.1.0"
+
+var (
+	whereExprs []string
+	chain      *filter.Chain
+)
+
+func main() {
+	config := cli.TransformConfig{
+		Name:        "filter",
+		Description: "Filter events using a --where expression chain",
+		Version:     version,
+	}
+
+	cli.RunTransformCLI(config, applyChain, addFlags)
+}
+
+func addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&whereExprs, "where", nil,
+		`Filter expression, e.g. 'type=="transfer" && amount>1000 && asset.code=="USDC"' (repeatable, ANDed together)`)
+}
+
+// applyChain lazily compiles --where into a filter.Chain on first use, then
+// runs every event through it.
+func applyChain(event map[string]interface{}) (map[string]interface{}, error) {
+	if chain == nil {
+		filters := make([]filter.Filter, 0, len(whereExprs))
+		for i, expr := range whereExprs {
+			f, err := filter.NewExprFilter(whereName(i), expr)
+			if err != nil {
+				return nil, err
+			}
+			filters = append(filters, f)
+		}
+		chain = filter.NewChain(filters...)
+	}
+
+	keep, out, err := chain.Apply(event)
+	if err != nil {
+		return nil, err
+	}
+	if !keep {
+		return nil, nil
+	}
+	return out, nil
+}
+
+func whereName(i int) string {
+	return fmt.Sprintf("where[%d]", i)
+}
@@ -5,32 +5,57 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
-	"regexp"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spf13/cobra"
+	"github.com/withObsrvr/nebu-processor-registry/pkg/batch"
+	"github.com/withObsrvr/nebu-processor-registry/pkg/metrics"
+	"github.com/withObsrvr/nebu-processor-registry/pkg/routing"
 	"github.com/withObsrvr/nebu/pkg/processor/cli"
 )
 
-const version = "0.1.0"
+const version = "0.3.0"
 
 var (
 	// Connection settings
-	natsURL      string
-	credsFile    string
-	connName     string
-	connTimeout  int
+	natsURL     string
+	credsFile   string
+	connName    string
+	connTimeout int
 
 	// Publishing settings
-	subjectTmpl string
+	subjectTmpl  string
 	useJetStream bool
-	strict      bool
+	strict       bool
+
+	// Batching settings (JetStream mode only)
+	batchSize     int
+	batchInterval time.Duration
+	maxPending    int
+
+	// Stream provisioning settings (JetStream mode only)
+	createStream    bool
+	streamName      string
+	streamSubjects  []string
+	streamRetention string
+	streamMaxAge    time.Duration
+	streamMaxBytes  int64
+	streamMaxMsgs   int64
+
+	// Observability settings
+	metricsAddr string
 
 	// Connection state (lazy initialized)
-	nc *nats.Conn
-	js nats.JetStreamContext
+	nc      *nats.Conn
+	js      nats.JetStreamContext
+	batcher *batch.Batcher
+
+	reg          *metrics.Registry
+	publishTotal *prometheus.CounterVec
 )
 
 func main() {
@@ -60,8 +85,14 @@ func setupCleanup() {
 	}()
 }
 
-// cleanup ensures NATS connection is properly closed
+// cleanup drains any buffered batch, waits for outstanding JetStream acks,
+// and closes the NATS connection.
 func cleanup() {
+	if batcher != nil {
+		if err := batcher.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "nats-sink: error flushing final batch: %v\n", err)
+		}
+	}
 	if nc != nil {
 		// Flush any pending messages before closing
 		nc.Flush()
@@ -88,35 +119,134 @@ func addFlags(cmd *cobra.Command) {
 		"Use JetStream for reliable delivery")
 	cmd.Flags().BoolVar(&strict, "strict", false,
 		"Fail on missing template variables (default: use '_unknown')")
+
+	// Batching flags (only apply when --jetstream is set)
+	cmd.Flags().IntVar(&batchSize, "batch-size", 100,
+		"Flush JetStream async publishes after this many events (jetstream mode only)")
+	cmd.Flags().DurationVar(&batchInterval, "batch-interval", time.Second,
+		"Flush JetStream async publishes after this long even if batch-size isn't reached")
+	cmd.Flags().IntVar(&maxPending, "max-pending", 256,
+		"Maximum number of unacknowledged async publishes (jetstream mode only)")
+
+	// Stream provisioning flags (only apply when --jetstream is set)
+	cmd.Flags().BoolVar(&createStream, "create-stream", false,
+		"Create (or update) the JetStream stream on startup instead of assuming it already exists (jetstream mode only)")
+	cmd.Flags().StringVar(&streamName, "stream-name", "",
+		"Stream name for --create-stream (required if set)")
+	cmd.Flags().StringSliceVar(&streamSubjects, "stream-subjects", nil,
+		"Subjects the stream captures for --create-stream (repeatable; default: --subject's static prefix plus '.>', or --subject itself if it has no template variables)")
+	cmd.Flags().StringVar(&streamRetention, "stream-retention", "limits",
+		"Stream retention policy for --create-stream: limits|interest|workqueue")
+	cmd.Flags().DurationVar(&streamMaxAge, "stream-max-age", 0,
+		"Discard messages older than this for --create-stream (0 = no limit)")
+	cmd.Flags().Int64Var(&streamMaxBytes, "stream-max-bytes", 0,
+		"Discard oldest messages once the stream exceeds this size for --create-stream (0 = no limit)")
+	cmd.Flags().Int64Var(&streamMaxMsgs, "stream-max-msgs", 0,
+		"Discard oldest messages once the stream exceeds this count for --create-stream (0 = no limit)")
+
+	// Observability flags
+	cmd.Flags().StringVar(&metricsAddr, "metrics-addr", "",
+		"Address to serve /metrics, /healthz, /readyz, and /debug/pprof on (empty disables)")
 }
 
-// publishToNats processes each event and publishes to NATS
+// publishToNats processes each event and publishes to NATS. In JetStream
+// mode, events are handed to a Batcher that issues PublishAsync calls and
+// waits for delivery confirmation on flush, giving producers real
+// backpressure instead of fire-and-forget semantics.
 func publishToNats(event map[string]interface{}) error {
 	// Lazy connect on first event
 	if nc == nil {
 		if err := connect(); err != nil {
 			return err
 		}
+		if useJetStream {
+			batcher = batch.New(batch.Config{
+				MaxSize:       batchSize,
+				FlushInterval: batchInterval,
+				MaxInFlight:   maxPending,
+			}, flushJetStreamBatch)
+		}
+		startMetrics()
 	}
 
-	// Resolve subject from template
-	subject := resolveSubject(subjectTmpl, event)
+	reg.EventsIn.Inc()
+
+	if useJetStream {
+		err := batcher.Add(event)
+		if err != nil {
+			publishTotal.WithLabelValues(subjectTmpl, "error").Inc()
+			return err
+		}
+		reg.EventsOut.Inc()
+		return nil
+	}
 
-	// Marshal event back to JSON
+	subject := resolveSubject(subjectTmpl, event)
 	data, err := json.Marshal(event)
 	if err != nil {
 		return fmt.Errorf("failed to marshal event: %w", err)
 	}
+	if err := nc.Publish(subject, data); err != nil {
+		publishTotal.WithLabelValues(subject, "error").Inc()
+		return fmt.Errorf("failed to publish to %s: %w", subject, err)
+	}
+	publishTotal.WithLabelValues(subject, "ok").Inc()
+	reg.EventsOut.Inc()
+	return nil
+}
 
-	// Publish to NATS
-	if useJetStream {
-		_, err = js.Publish(subject, data)
-	} else {
-		err = nc.Publish(subject, data)
+// startMetrics sets up the Prometheus registry and, if --metrics-addr is
+// set, the /metrics, /healthz, /readyz, /debug/pprof admin listener.
+func startMetrics() {
+	reg = metrics.NewRegistry("nats-sink")
+	publishTotal = reg.NATSPublishCounter()
+
+	if metricsAddr == "" {
+		return
 	}
 
-	if err != nil {
-		return fmt.Errorf("failed to publish to %s: %w", subject, err)
+	admin := reg.NewAdminServer(metricsAddr, func() bool { return nc != nil && nc.IsConnected() })
+	errCh := make(chan error, 1)
+	admin.Start(errCh)
+	go func() {
+		if err := <-errCh; err != nil {
+			fmt.Fprintf(os.Stderr, "nats-sink: admin server error: %v\n", err)
+		}
+	}()
+}
+
+// flushJetStreamBatch publishes a batch asynchronously and blocks until
+// every publish in the batch is acknowledged (or times out), so a flush
+// failure surfaces to the pipeline instead of being silently dropped.
+func flushJetStreamBatch(events []map[string]interface{}) error {
+	futures := make([]nats.PubAckFuture, 0, len(events))
+
+	for _, event := range events {
+		subject := resolveSubject(subjectTmpl, event)
+		data, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event: %w", err)
+		}
+
+		future, err := js.PublishAsync(subject, data)
+		if err != nil {
+			return fmt.Errorf("failed to publish to %s: %w", subject, err)
+		}
+		futures = append(futures, future)
+	}
+
+	select {
+	case <-js.PublishAsyncComplete():
+	case <-time.After(batchInterval + 10*time.Second):
+		return fmt.Errorf("timed out waiting for %d JetStream acks", len(futures))
+	}
+
+	for _, future := range futures {
+		select {
+		case err := <-future.Err():
+			return fmt.Errorf("JetStream publish failed: %w", err)
+		default:
+		}
 	}
 
 	return nil
@@ -130,6 +260,11 @@ func connect() error {
 		// Production resilience: reconnect forever to handle network blips
 		nats.MaxReconnects(-1),
 		nats.ReconnectWait(2 * nats.DefaultTimeout),
+		nats.ReconnectHandler(func(*nats.Conn) {
+			if reg != nil {
+				reg.NATSReconnects.Inc()
+			}
+		}),
 	}
 
 	// Add credentials if provided
@@ -146,87 +281,101 @@ func connect() error {
 
 	// Setup JetStream if requested
 	if useJetStream {
-		js, err = nc.JetStream()
+		js, err = nc.JetStream(nats.PublishAsyncMaxPending(maxPending))
 		if err != nil {
 			nc.Close()
 			return fmt.Errorf("failed to create JetStream context: %w", err)
 		}
+		if createStream {
+			if err := ensureStream(); err != nil {
+				nc.Close()
+				return err
+			}
+		}
 	}
 
 	return nil
 }
 
-// resolveSubject resolves template variables in the subject string
-// Supports:
-//   - {key} for top-level fields
-//   - {nested.key} for nested fields with dot notation
-func resolveSubject(template string, event map[string]interface{}) string {
-	// If no template variables, return as-is
-	if !strings.Contains(template, "{") {
-		return template
+// ensureStream creates --stream-name if it doesn't exist, or updates its
+// limits/retention if it does, so a fresh environment can stand up its
+// JetStream stream from sink flags instead of a separate `nats stream add`
+// step.
+func ensureStream() error {
+	if streamName == "" {
+		return fmt.Errorf("--create-stream requires --stream-name")
 	}
 
-	result := template
-
-	// Find all {var} patterns
-	re := regexp.MustCompile(`\{([^}]+)\}`)
-	matches := re.FindAllStringSubmatch(template, -1)
+	subjects := streamSubjects
+	if len(subjects) == 0 {
+		subjects = []string{wildcardSubject(subjectTmpl)}
+	}
 
-	for _, match := range matches {
-		placeholder := match[0] // e.g., "{type}"
-		path := match[1]        // e.g., "type" or "transfer.assetCode"
+	retention, err := parseRetentionPolicy(streamRetention)
+	if err != nil {
+		return err
+	}
 
-		// Resolve value from event
-		value := resolveValue(event, path)
+	cfg := &nats.StreamConfig{
+		Name:      streamName,
+		Subjects:  subjects,
+		Retention: retention,
+		MaxAge:    streamMaxAge,
+		MaxBytes:  streamMaxBytes,
+		MaxMsgs:   streamMaxMsgs,
+	}
 
-		// Replace placeholder
-		result = strings.ReplaceAll(result, placeholder, value)
+	if _, err := js.StreamInfo(streamName); err == nil {
+		_, err := js.UpdateStream(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to update stream %s: %w", streamName, err)
+		}
+		return nil
 	}
 
-	return result
+	if _, err := js.AddStream(cfg); err != nil {
+		return fmt.Errorf("failed to create stream %s: %w", streamName, err)
+	}
+	return nil
 }
 
-// resolveValue resolves a value from the event using dot-notation path
-func resolveValue(event map[string]interface{}, path string) string {
-	// Split path by dots
-	parts := strings.Split(path, ".")
-
-	// Navigate through nested maps
-	var current interface{} = event
-	for _, part := range parts {
-		// Check if current is a map
-		m, ok := current.(map[string]interface{})
-		if !ok {
-			return handleMissingValue(path)
-		}
-
-		// Get value
-		val, exists := m[part]
-		if !exists {
-			return handleMissingValue(path)
+// wildcardSubject derives a stream subject filter from a --subject
+// template like "nebu.events.{type}.{asset_code}" by keeping everything
+// before the first template variable and replacing the rest with the
+// JetStream "match everything after this" wildcard.
+func wildcardSubject(tmpl string) string {
+	if i := strings.Index(tmpl, "{"); i >= 0 {
+		prefix := strings.TrimSuffix(tmpl[:i], ".")
+		if prefix == "" {
+			return ">"
 		}
-
-		current = val
+		return prefix + ".>"
 	}
+	return tmpl
+}
 
-	// Convert final value to string and sanitize for NATS subjects
-	strVal := fmt.Sprint(current)
-
-	// CRITICAL: Sanitize dots and spaces to prevent breaking NATS subject hierarchy
-	// Example: asset "Funny.Token" would create "stellar.Funny.Token" breaking wildcard subscriptions
-	strVal = strings.ReplaceAll(strVal, ".", "_")
-	strVal = strings.ReplaceAll(strVal, " ", "_")
-
-	return strVal
+func parseRetentionPolicy(policy string) (nats.RetentionPolicy, error) {
+	switch policy {
+	case "limits", "":
+		return nats.LimitsPolicy, nil
+	case "interest":
+		return nats.InterestPolicy, nil
+	case "workqueue":
+		return nats.WorkQueuePolicy, nil
+	default:
+		return 0, fmt.Errorf("unknown --stream-retention %q: expected limits, interest, or workqueue", policy)
+	}
 }
 
-// handleMissingValue handles missing template variables based on strict mode
-func handleMissingValue(path string) string {
-	if strict {
-		fmt.Fprintf(os.Stderr, "Error: template variable '%s' not found in event\n", path)
-		os.Exit(1)
+// resolveSubject resolves template variables in the subject string using
+// the shared routing.Resolve (also used by processors/router), falling
+// back to "_unknown" or exiting per --strict exactly as before.
+func resolveSubject(template string, event map[string]interface{}) string {
+	subject, err := routing.Resolve(template, event, strict)
+	if err != nil {
+		routing.FatalMissing(err)
 	}
-	return "_unknown"
+	return subject
 }
 
 // getEnvOrDefault gets environment variable or returns default
@@ -0,0 +1,336 @@
+// Package main provides a standalone CLI for the nats-source origin processor.
+//
+// This processor subscribes to a NATS JetStream subject and replays the
+// messages into the nebu pipeline as newline-delimited JSON on stdout. It is
+// the symmetric counterpart to nats-sink: one ingestion pipeline can publish
+// to JetStream, and many independent transform/sink chains can consume from
+// it, including replaying historical data by sequence or time.
+//
+// Usage:
+//
+//	# Tail a subject from now on
+//	nats-source --url nats://localhost:4222 --stream STELLAR --subject stellar.>
+//
+//	# Replay from a specific sequence with a durable consumer
+//	nats-source --stream STELLAR --subject stellar.transfer \
+//	  --durable replay-worker --start-seq 104200 | \
+//	  usdc-filter | json-file-sink --out replay.jsonl
+//
+//	# Replay everything since a point in time
+//	nats-source --stream STELLAR --subject stellar.> \
+//	  --start-time 2026-07-01T00:00:00Z
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/spf13/cobra"
+	"github.com/withObsrvr/nebu-processor-registry/pkg/checkpoint"
+)
+
+const version = "0.1.1"
+
+var (
+	// Connection settings
+	natsURL   string
+	credsFile string
+	connName  string
+
+	// Subscription settings
+	subjects    []string
+	streamName  string
+	durableName string
+	ackWait     time.Duration
+	maxInFlight int
+
+	// Replay settings
+	startSeq  uint64
+	startTime string
+
+	// Checkpoint/resume settings
+	ckptOpts  *checkpoint.Options
+	ckptStore checkpoint.Store
+
+	// Connection state (lazy initialized)
+	nc *nats.Conn
+	js nats.JetStreamContext
+	sc *nats.Subscription
+)
+
+func main() {
+	setupCleanup()
+
+	cmd := &cobra.Command{
+		Use:     "nats-source",
+		Short:   "Replay events from a NATS JetStream subject into the nebu pipeline",
+		Version: version,
+		RunE:    run,
+	}
+	addFlags(cmd)
+
+	if err := cmd.Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		cleanup()
+		os.Exit(1)
+	}
+
+	cleanup()
+}
+
+func addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&natsURL, "url", getEnvOrDefault("NATS_URL", "nats://localhost:4222"),
+		"NATS server URL (or set NATS_URL)")
+	cmd.Flags().StringVar(&credsFile, "creds", getEnvOrDefault("NATS_CREDS", ""),
+		"Path to NATS credentials file (optional, or set NATS_CREDS)")
+	cmd.Flags().StringVar(&connName, "name", "nats-source",
+		"Connection name for monitoring")
+
+	cmd.Flags().StringSliceVar(&subjects, "subject", []string{"events"},
+		"Subject(s) to subscribe to (repeatable, supports wildcards)")
+	cmd.Flags().StringVar(&streamName, "stream", "",
+		"JetStream stream name to bind the consumer to (required)")
+	cmd.Flags().StringVar(&durableName, "durable", "",
+		"Durable consumer name (empty = ephemeral consumer)")
+	cmd.Flags().DurationVar(&ackWait, "ack-wait", 30*time.Second,
+		"How long JetStream waits for an ack before redelivering")
+	cmd.Flags().IntVar(&maxInFlight, "max-in-flight", 256,
+		"Maximum number of unacked messages in flight")
+
+	cmd.Flags().Uint64Var(&startSeq, "start-seq", 0,
+		"Replay starting from this stream sequence (0 = use --start-time or deliver new)")
+	cmd.Flags().StringVar(&startTime, "start-time", "",
+		"Replay starting from this RFC3339 timestamp (overrides --start-seq)")
+
+	ckptOpts = checkpoint.AddFlags(cmd)
+
+	cmd.MarkFlagRequired("stream")
+}
+
+// resolveStartSeq applies --resume, treating the JetStream stream sequence
+// as the checkpointed "ledger" cursor: if a later sequence was already
+// acked on a previous run, replay resumes from just past it instead of
+// --start-seq.
+func resolveStartSeq() (uint64, error) {
+	if ckptOpts.StoreDSN == "" {
+		return startSeq, nil
+	}
+
+	var err error
+	ckptStore, err = checkpoint.Open(ckptOpts.StoreDSN)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open checkpoint store: %w", err)
+	}
+
+	resumed, err := checkpoint.Resume(ckptStore, ckptOpts, "nats-source", uint32(startSeq))
+	if err != nil {
+		return 0, err
+	}
+	return uint64(resumed), nil
+}
+
+// run connects to JetStream, subscribes, and streams messages to stdout as
+// newline-delimited JSON until the context is canceled.
+func run(cmd *cobra.Command, args []string) error {
+	resumedSeq, err := resolveStartSeq()
+	if err != nil {
+		return err
+	}
+	startSeq = resumedSeq
+
+	if err := connect(); err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(os.Stdout)
+	defer writer.Flush()
+
+	msgs := make(chan *nats.Msg, maxInFlight)
+	if err := subscribe(msgs); err != nil {
+		return err
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+
+	encoder := json.NewEncoder(writer)
+	for {
+		select {
+		case <-sigCh:
+			return nil
+		case msg, ok := <-msgs:
+			if !ok {
+				return nil
+			}
+			event, err := toEvent(msg)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "nats-source: skipping malformed message: %v\n", err)
+				msg.Ack()
+				continue
+			}
+
+			if err := encoder.Encode(event); err != nil {
+				return fmt.Errorf("failed to write event: %w", err)
+			}
+			if err := writer.Flush(); err != nil {
+				return fmt.Errorf("failed to flush stdout: %w", err)
+			}
+			msg.Ack()
+			saveCheckpoint(msg)
+		}
+	}
+}
+
+// saveCheckpoint persists the stream sequence of a just-acked message as
+// the new checkpoint, if checkpointing is enabled. Saving after Ack (rather
+// than after encoding) keeps the at-least-once guarantee: a crash between
+// encode and ack simply replays the message on the next run.
+func saveCheckpoint(msg *nats.Msg) {
+	if ckptStore == nil {
+		return
+	}
+	meta, err := msg.Metadata()
+	if err != nil {
+		return
+	}
+
+	shard := ckptOpts.CheckpointID
+	if shard == "" {
+		shard = "nats-source"
+	}
+	if err := ckptStore.Save("nats-source", shard, uint32(meta.Sequence.Stream)); err != nil {
+		fmt.Fprintf(os.Stderr, "nats-source: failed to save checkpoint: %v\n", err)
+	}
+}
+
+// subscribe creates the JetStream pull/push subscription(s) for the
+// configured subjects, honoring replay-by-sequence/time options.
+func subscribe(out chan<- *nats.Msg) error {
+	opts := []nats.SubOpt{
+		nats.ManualAck(),
+		nats.AckWait(ackWait),
+		nats.MaxAckPending(maxInFlight),
+	}
+
+	if durableName != "" {
+		opts = append(opts, nats.Durable(durableName))
+	}
+
+	switch {
+	case startTime != "":
+		t, err := time.Parse(time.RFC3339, startTime)
+		if err != nil {
+			return fmt.Errorf("invalid --start-time: %w", err)
+		}
+		opts = append(opts, nats.DeliverByStartTime(t))
+	case startSeq > 0:
+		opts = append(opts, nats.DeliverByStartSequence(startSeq))
+	default:
+		opts = append(opts, nats.DeliverNew())
+	}
+
+	for _, subject := range subjects {
+		handler := func(msg *nats.Msg) { out <- msg }
+		sub, err := js.Subscribe(subject, handler, append(opts, nats.BindStream(streamName))...)
+		if err != nil {
+			return fmt.Errorf("failed to subscribe to %s: %w", subject, err)
+		}
+		sc = sub
+	}
+
+	return nil
+}
+
+// toEvent translates a JetStream message (payload + metadata) into the
+// map[string]interface{} event shape used across the pipeline, so downstream
+// transforms can dedupe on stream/seq/timestamp.
+func toEvent(msg *nats.Msg) (map[string]interface{}, error) {
+	var event map[string]interface{}
+	if err := json.Unmarshal(msg.Data, &event); err != nil {
+		return nil, fmt.Errorf("invalid JSON payload: %w", err)
+	}
+
+	meta, err := msg.Metadata()
+	if err == nil {
+		event["_jetstream"] = map[string]interface{}{
+			"stream":      meta.Stream,
+			"consumer":    meta.Consumer,
+			"subject":     msg.Subject,
+			"seq":         meta.Sequence.Stream,
+			"consumerSeq": meta.Sequence.Consumer,
+			"timestamp":   meta.Timestamp.Unix(),
+			"delivered":   meta.NumDelivered,
+		}
+
+		// Feed the stream sequence into postgres-sink's existing TOID
+		// extraction path (it checks event["toid"] before falling back to
+		// toid.FromEvent), so downstream inserts dedupe on redelivery
+		// without the sink needing to know anything about JetStream.
+		if _, exists := event["toid"]; !exists {
+			event["toid"] = meta.Sequence.Stream
+		}
+	}
+
+	return event, nil
+}
+
+// connect establishes the connection to NATS and a JetStream context.
+func connect() error {
+	opts := []nats.Option{
+		nats.Name(connName),
+		nats.MaxReconnects(-1),
+		nats.ReconnectWait(2 * nats.DefaultTimeout),
+	}
+
+	if credsFile != "" {
+		opts = append(opts, nats.UserCredentials(credsFile))
+	}
+
+	var err error
+	nc, err = nats.Connect(natsURL, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to connect to NATS at %s: %w", natsURL, err)
+	}
+
+	js, err = nc.JetStream()
+	if err != nil {
+		nc.Close()
+		return fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+
+	return nil
+}
+
+// setupCleanup registers signal handlers so an interrupt mid-replay still
+// unsubscribes cleanly instead of leaving a dangling consumer.
+func setupCleanup() {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-c
+		cleanup()
+		os.Exit(0)
+	}()
+}
+
+func cleanup() {
+	if sc != nil {
+		sc.Unsubscribe()
+	}
+	if nc != nil {
+		nc.Close()
+	}
+}
+
+func getEnvOrDefault(key, defaultValue string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return defaultValue
+}
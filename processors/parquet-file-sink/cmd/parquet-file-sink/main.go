@@ -0,0 +1,283 @@
+// Package main implements a Parquet file sink for analytical workloads.
+//
+// json-file-sink writes one JSON object per line, which is easy to inspect
+// but expensive for downstream tools like Spark or DuckDB to scan. This
+// sink instead infers a column schema from the event stream and writes
+// row-group Parquet files, rotating by size or time and publishing each
+// file atomically so a catalog step never sees a partial file.
+//
+// Usage:
+//
+//	token-transfer --start-ledger 60200000 --end-ledger 60200100 | \
+//	  parquet-file-sink --out ./transfers/ --codec zstd --rotate-interval 1h
+//
+//	# Direct-to-S3 with multipart upload
+//	token-transfer --start-ledger 60200000 --end-ledger 60200100 | \
+//	  parquet-file-sink --out s3://my-bucket/transfers/ --rotate-bytes 268435456
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/withObsrvr/nebu/pkg/processor/cli"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+var version = "0.1.0"
+
+var (
+	outPath       string
+	codecName     string
+	rowGroupBytes int64
+	rotateBytes   int64
+	rotateEvery   time.Duration
+	schemaSample  int
+
+	r *rotator
+)
+
+func main() {
+	setupCleanup()
+
+	config := cli.SinkConfig{
+		Name:        "parquet-file-sink",
+		Description: "Write events to row-group Parquet files with size/time rotation",
+		Version:     version,
+	}
+
+	cli.RunSinkCLI(config, writeEvent, addFlags)
+
+	closeCurrent()
+}
+
+func addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&outPath, "out", "./", "Output directory, or s3://bucket/prefix/ for direct-to-S3 upload")
+	cmd.Flags().StringVar(&codecName, "codec", "snappy", "Compression codec: snappy|zstd|gzip|none")
+	cmd.Flags().Int64Var(&rowGroupBytes, "rowgroup-bytes", 128*1024*1024, "Target uncompressed row-group size in bytes")
+	cmd.Flags().Int64Var(&rotateBytes, "rotate-bytes", 256*1024*1024, "Rotate to a new file after this many bytes, given as a plain integer; unit suffixes such as MiB are not accepted (0 disables)")
+	cmd.Flags().DurationVar(&rotateEvery, "rotate-interval", time.Hour, "Rotate to a new file after this long even if rotate-bytes isn't reached (0 disables)")
+	cmd.Flags().IntVar(&schemaSample, "schema-sample", 1000, "Number of events to sample before locking the inferred schema")
+}
+
+// writeEvent is the per-event callback cli.RunSinkCLI drives.
+func writeEvent(event map[string]interface{}) error {
+	if r == nil {
+		codec, err := parseCodec(codecName)
+		if err != nil {
+			return err
+		}
+		st, err := newStore(outPath)
+		if err != nil {
+			return err
+		}
+		r = newRotator(st, codec)
+	}
+	return r.write(event)
+}
+
+func parseCodec(name string) (parquet.CompressionCodec, error) {
+	switch name {
+	case "snappy":
+		return parquet.CompressionCodec_SNAPPY, nil
+	case "zstd":
+		return parquet.CompressionCodec_ZSTD, nil
+	case "gzip":
+		return parquet.CompressionCodec_GZIP, nil
+	case "none":
+		return parquet.CompressionCodec_UNCOMPRESSED, nil
+	default:
+		return 0, fmt.Errorf("unknown --codec %q (want snappy, zstd, gzip, or none)", name)
+	}
+}
+
+// rotator owns the currently-open Parquet writer, decides when to roll it
+// over, and publishes a manifest line to stdout once a file is closed.
+type rotator struct {
+	st     store
+	codec  parquet.CompressionCodec
+	schema *Schema
+
+	seq       int
+	pw        *writer.JSONWriter
+	tmpName   string
+	finalName string
+	openedAt  time.Time
+	rowCount  int64
+	byteEstim int64
+
+	// pending holds events observed before the schema has locked, so the
+	// writer can be opened once against the final sampled column set
+	// instead of against whatever the first event alone looked like.
+	pending []map[string]interface{}
+}
+
+func newRotator(st store, codec parquet.CompressionCodec) *rotator {
+	return &rotator{
+		st:     st,
+		codec:  codec,
+		schema: NewSchema(schemaSample),
+	}
+}
+
+func (r *rotator) write(event map[string]interface{}) error {
+	needsRotation := r.schema.Observe(event)
+	if needsRotation {
+		if err := r.rotate(); err != nil {
+			return err
+		}
+		r.schema.Reset()
+		r.schema.Observe(event) // re-sample starting from the row that triggered evolution
+	}
+
+	if !r.schema.Locked() {
+		// Still inside the --schema-sample window: buffer the row instead
+		// of opening the writer now, since the schema's final column set
+		// isn't known until sampling ends — opening against event #1 alone
+		// would silently drop any column first seen in event #2..N.
+		r.pending = append(r.pending, event)
+		return nil
+	}
+
+	for _, pendingEvent := range r.pending {
+		if err := r.writeRow(pendingEvent); err != nil {
+			return err
+		}
+	}
+	r.pending = nil
+
+	return r.writeRow(event)
+}
+
+// writeRow opens the writer against the now-locked schema if it isn't
+// already open, writes a single row, and rotates if the new file has
+// crossed a size or time threshold.
+func (r *rotator) writeRow(event map[string]interface{}) error {
+	if r.pw == nil {
+		if err := r.open(); err != nil {
+			return err
+		}
+	}
+
+	row := r.schema.Row(event)
+	if err := r.pw.Write(row); err != nil {
+		return fmt.Errorf("failed to write row: %w", err)
+	}
+	r.rowCount++
+	r.byteEstim += int64(len(row))
+
+	if r.shouldRotate() {
+		return r.rotate()
+	}
+	return nil
+}
+
+func (r *rotator) shouldRotate() bool {
+	if rotateBytes > 0 && r.byteEstim >= rotateBytes {
+		return true
+	}
+	if rotateEvery > 0 && !r.openedAt.IsZero() && time.Since(r.openedAt) >= rotateEvery {
+		return true
+	}
+	return false
+}
+
+func (r *rotator) open() error {
+	r.seq++
+	r.finalName = fmt.Sprintf("events-%06d.parquet", r.seq)
+
+	pf, tmpName, err := r.st.open(r.finalName)
+	if err != nil {
+		return err
+	}
+	r.tmpName = tmpName
+
+	pw, err := writer.NewJSONWriter(r.schema.JSONTag(), pf, 4)
+	if err != nil {
+		pf.Close()
+		return fmt.Errorf("failed to open parquet writer for %s: %w", r.finalName, err)
+	}
+	pw.RowGroupSize = rowGroupBytes
+	pw.CompressionType = r.codec
+
+	r.pw = pw
+	r.openedAt = time.Now()
+	r.rowCount = 0
+	r.byteEstim = 0
+	return nil
+}
+
+// rotate flushes and closes the current file, publishes it under its final
+// name, and emits a sidecar manifest line to stdout so a downstream step
+// can register the file with a catalog.
+func (r *rotator) rotate() error {
+	if r.pw == nil {
+		return nil
+	}
+
+	if err := r.pw.WriteStop(); err != nil {
+		return fmt.Errorf("failed to flush %s: %w", r.finalName, err)
+	}
+	if err := r.pw.PFile.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", r.finalName, err)
+	}
+	if err := r.st.publish(r.tmpName, r.finalName); err != nil {
+		return err
+	}
+
+	manifest, _ := json.Marshal(map[string]interface{}{
+		"file":    r.finalName,
+		"rows":    r.rowCount,
+		"codec":   codecName,
+		"columns": r.schema.Columns(),
+	})
+	fmt.Println(string(manifest))
+
+	r.pw = nil
+	return nil
+}
+
+func closeCurrent() {
+	if r != nil {
+		if err := r.finish(); err != nil {
+			fmt.Fprintf(os.Stderr, "parquet-file-sink: error flushing buffered rows: %v\n", err)
+		}
+		if err := r.rotate(); err != nil {
+			fmt.Fprintf(os.Stderr, "parquet-file-sink: error closing final file: %v\n", err)
+		}
+	}
+}
+
+// finish flushes any rows still buffered in the schema-sample window, for
+// a stream that ends before --schema-sample events have been observed —
+// without this, those rows would never be written at all.
+func (r *rotator) finish() error {
+	if len(r.pending) == 0 {
+		return nil
+	}
+	r.schema.Lock()
+	pending := r.pending
+	r.pending = nil
+	for _, event := range pending {
+		if err := r.writeRow(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setupCleanup() {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-c
+		closeCurrent()
+		os.Exit(0)
+	}()
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xitongsys/parquet-go/parquet"
+)
+
+// TestRotatorWrite_BuffersUntilSchemaLocks verifies that columns first seen
+// after event #1 (but still inside the --schema-sample window) make it into
+// the written file, instead of being silently dropped because the writer
+// opened against event #1's columns alone.
+func TestRotatorWrite_BuffersUntilSchemaLocks(t *testing.T) {
+	st, err := newLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	schemaSample = 3
+	r := newRotator(st, parquet.CompressionCodec_SNAPPY)
+
+	require.NoError(t, r.write(map[string]interface{}{"a": float64(1)}))
+	// Before the sample window closes, the writer must not have opened yet,
+	// or "b" below would never make it into the file's column set.
+	assert.Nil(t, r.pw, "writer should stay closed while the schema is still sampling")
+
+	require.NoError(t, r.write(map[string]interface{}{"a": float64(2), "b": "x"}))
+	require.NoError(t, r.write(map[string]interface{}{"a": float64(3), "b": "y"}))
+
+	// The third event locks the schema and flushes the two buffered rows
+	// plus itself, all three carrying both "a" and "b".
+	assert.NotNil(t, r.pw, "writer should open once the schema locks")
+	assert.Empty(t, r.pending)
+	assert.ElementsMatch(t, []string{"a", "b"}, r.schema.Columns())
+	assert.EqualValues(t, 3, r.rowCount)
+}
+
+// TestRotatorFinish_FlushesShortStream verifies that a stream shorter than
+// --schema-sample still gets its buffered rows written instead of silently
+// losing them when the process shuts down before the sample window fills.
+func TestRotatorFinish_FlushesShortStream(t *testing.T) {
+	st, err := newLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	schemaSample = 1000
+	r := newRotator(st, parquet.CompressionCodec_SNAPPY)
+
+	require.NoError(t, r.write(map[string]interface{}{"a": float64(1)}))
+	require.NoError(t, r.write(map[string]interface{}{"a": float64(2), "b": "x"}))
+	assert.Nil(t, r.pw, "writer should still be unopened; the sample window hasn't closed")
+
+	require.NoError(t, r.finish())
+	assert.NotNil(t, r.pw, "finish should force the schema to lock and open the writer")
+	assert.Empty(t, r.pending)
+	assert.EqualValues(t, 2, r.rowCount)
+}
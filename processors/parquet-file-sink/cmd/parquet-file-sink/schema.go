@@ -0,0 +1,250 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// colType is the inferred Parquet column type for one event field. Types
+// only ever widen (bool < int64 < float64 < string); once a column needs a
+// wider type than the schema currently has, the schema is out of date and
+// the caller must rotate to a new file rather than rewrite an open one.
+type colType int
+
+const (
+	typeBool colType = iota
+	typeInt64
+	typeFloat64
+	typeString
+)
+
+func valueType(v interface{}) colType {
+	switch val := v.(type) {
+	case bool:
+		return typeBool
+	case float64:
+		if val == float64(int64(val)) {
+			return typeInt64
+		}
+		return typeFloat64
+	case string:
+		return typeString
+	default:
+		// nested objects/arrays and anything else are flattened to their
+		// JSON text rather than modeled as nested Parquet groups.
+		return typeString
+	}
+}
+
+// widen returns the narrowest type that can represent both a and b.
+func widen(a, b colType) colType {
+	if a == b {
+		return a
+	}
+	if a > b {
+		a, b = b, a
+	}
+	if a == typeBool {
+		return typeString
+	}
+	if a == typeInt64 && b == typeFloat64 {
+		return typeFloat64
+	}
+	return typeString
+}
+
+// Schema infers a Parquet column set from the first sampleSize events, then
+// locks: every event after that is checked against the locked column set,
+// and Observe reports whether the event needs a column the schema doesn't
+// have (or a wider type than it has), which the caller handles by rotating
+// to a new file with the evolved schema.
+type Schema struct {
+	sampleSize int
+
+	mu      sync.Mutex
+	order   []string
+	types   map[string]colType
+	sampled int
+	locked  bool
+}
+
+// NewSchema creates a Schema that infers its columns from the first
+// sampleSize events it observes (minimum 1).
+func NewSchema(sampleSize int) *Schema {
+	if sampleSize < 1 {
+		sampleSize = 1
+	}
+	return &Schema{
+		sampleSize: sampleSize,
+		types:      make(map[string]colType),
+	}
+}
+
+// Observe folds event into the schema during the sampling window. Once the
+// schema is locked, Observe instead reports whether event needs a column
+// or type the schema doesn't already have.
+func (s *Schema) Observe(event map[string]interface{}) (needsRotation bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.locked {
+		s.merge(event)
+		s.sampled++
+		if s.sampled >= s.sampleSize {
+			s.locked = true
+		}
+		return false
+	}
+
+	for k, v := range event {
+		t, ok := s.types[k]
+		if !ok {
+			return true
+		}
+		if widen(t, valueType(v)) != t {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Schema) merge(event map[string]interface{}) {
+	for k, v := range event {
+		t := valueType(v)
+		if existing, ok := s.types[k]; ok {
+			s.types[k] = widen(existing, t)
+			continue
+		}
+		s.types[k] = t
+		s.order = append(s.order, k)
+	}
+}
+
+// Reset clears a locked schema so the next Observe call re-samples from
+// scratch, used after a rotation triggered by schema evolution.
+func (s *Schema) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.order = nil
+	s.types = make(map[string]colType)
+	s.sampled = 0
+	s.locked = false
+}
+
+// Locked reports whether the schema has finished its sampling window and
+// stopped accepting new columns.
+func (s *Schema) Locked() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.locked
+}
+
+// Lock forces the schema to stop sampling immediately, used when the event
+// stream ends before sampleSize events have been observed so the buffered
+// rows aren't held open forever waiting for a sample window that will
+// never fill.
+func (s *Schema) Lock() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.locked = true
+}
+
+// Columns returns the schema's column names in stable (first-seen) order.
+func (s *Schema) Columns() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cols := make([]string, len(s.order))
+	copy(cols, s.order)
+	return cols
+}
+
+// JSONTag renders the schema as the tag-based JSON definition
+// xitongsys/parquet-go's JSON writer expects. Every column is OPTIONAL
+// since events are dynamic maps and any key may be absent from a given row.
+func (s *Schema) JSONTag() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Sort for deterministic output across runs even though order is
+	// first-seen for the benefit of anyone reading row JSON by eye.
+	cols := make([]string, len(s.order))
+	copy(cols, s.order)
+	sort.Strings(cols)
+
+	fields := make([]string, 0, len(cols))
+	for _, name := range cols {
+		fields = append(fields, fieldTag(name, s.types[name]))
+	}
+
+	return fmt.Sprintf(`{"Tag":"name=root, repetitiontype=REQUIRED","Fields":[%s]}`, strings.Join(fields, ","))
+}
+
+func fieldTag(name string, t colType) string {
+	switch t {
+	case typeBool:
+		return fmt.Sprintf(`{"Tag":"name=%s, type=BOOLEAN, repetitiontype=OPTIONAL"}`, name)
+	case typeInt64:
+		return fmt.Sprintf(`{"Tag":"name=%s, type=INT64, repetitiontype=OPTIONAL"}`, name)
+	case typeFloat64:
+		return fmt.Sprintf(`{"Tag":"name=%s, type=DOUBLE, repetitiontype=OPTIONAL"}`, name)
+	default:
+		return fmt.Sprintf(`{"Tag":"name=%s, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"}`, name)
+	}
+}
+
+// Row encodes event as a JSON object restricted to the schema's columns, in
+// the shape xitongsys/parquet-go's JSON writer expects for a single row.
+// Values of a narrower type than the schema column (e.g. an int in a float
+// column) are coerced; values outside the schema are dropped (the caller is
+// expected to have already rotated on Observe's needsRotation signal).
+func (s *Schema) Row(event map[string]interface{}) string {
+	s.mu.Lock()
+	cols := make([]string, len(s.order))
+	copy(cols, s.order)
+	types := make(map[string]colType, len(s.types))
+	for k, v := range s.types {
+		types[k] = v
+	}
+	s.mu.Unlock()
+
+	parts := make([]string, 0, len(cols))
+	for _, name := range cols {
+		v, ok := event[name]
+		if !ok {
+			parts = append(parts, fmt.Sprintf("%q:null", name))
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%q:%s", name, encodeValue(types[name], v)))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func encodeValue(t colType, v interface{}) string {
+	switch t {
+	case typeBool:
+		b, _ := v.(bool)
+		return fmt.Sprintf("%t", b)
+	case typeInt64:
+		f, _ := v.(float64)
+		return fmt.Sprintf("%d", int64(f))
+	case typeFloat64:
+		switch n := v.(type) {
+		case float64:
+			return fmt.Sprintf("%g", n)
+		default:
+			return "0"
+		}
+	default:
+		if s, ok := v.(string); ok {
+			return fmt.Sprintf("%q", s)
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%q", fmt.Sprint(v))
+		}
+		return fmt.Sprintf("%q", string(b))
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	parquetsource "github.com/xitongsys/parquet-go-source/local"
+	s3source "github.com/xitongsys/parquet-go-source/s3"
+	"github.com/xitongsys/parquet-go/source"
+)
+
+// store opens Parquet files at a destination and publishes them atomically
+// once a rotation is complete. Local and S3 destinations need different
+// "atomic rename" strategies, so both are hidden behind this interface.
+type store interface {
+	// open returns a ParquetFile the writer can stream row groups into, plus
+	// the temporary name that will become finalName once publish is called.
+	open(finalName string) (pf source.ParquetFile, tmpName string, err error)
+	// publish makes tmpName visible as finalName.
+	publish(tmpName, finalName string) error
+}
+
+// newStore builds a store for --out, which is either a local directory or
+// an "s3://bucket/prefix/" URL.
+func newStore(out string) (store, error) {
+	if strings.HasPrefix(out, "s3://") {
+		return newS3Store(out)
+	}
+	return newLocalStore(out)
+}
+
+// localStore writes to "<name>.parquet.tmp" and renames to "<name>.parquet"
+// once the writer has flushed and closed, so a reader never sees a partial
+// file under its final name.
+type localStore struct {
+	dir string
+}
+
+func newLocalStore(dir string) (*localStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory %s: %w", dir, err)
+	}
+	return &localStore{dir: dir}, nil
+}
+
+func (s *localStore) open(finalName string) (source.ParquetFile, string, error) {
+	tmpName := finalName + ".tmp"
+	pf, err := parquetsource.NewLocalFileWriter(path.Join(s.dir, tmpName))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open %s: %w", tmpName, err)
+	}
+	return pf, tmpName, nil
+}
+
+func (s *localStore) publish(tmpName, finalName string) error {
+	return os.Rename(path.Join(s.dir, tmpName), path.Join(s.dir, finalName))
+}
+
+// s3Store writes directly to "<prefix><name>.parquet.tmp" via a background
+// multipart upload (xitongsys/parquet-go-source/s3 streams into an
+// s3manager.Uploader internally) and "publishes" by server-side copying the
+// object to its final key and deleting the temporary one, since S3 has no
+// rename.
+type s3Store struct {
+	client *s3.Client
+	bucket string
+	prefix string
+}
+
+func newS3Store(out string) (*s3Store, error) {
+	rest := strings.TrimPrefix(out, "s3://")
+	bucket, prefix, _ := strings.Cut(rest, "/")
+	if bucket == "" {
+		return nil, fmt.Errorf("invalid s3 destination %q: missing bucket", out)
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	return &s3Store{
+		client: s3.NewFromConfig(cfg),
+		bucket: bucket,
+		prefix: prefix,
+	}, nil
+}
+
+func (s *s3Store) open(finalName string) (source.ParquetFile, string, error) {
+	tmpName := finalName + ".tmp"
+	pf, err := s3source.NewS3FileWriterWithClient(context.Background(), s.client, s.bucket, path.Join(s.prefix, tmpName), nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open s3://%s/%s: %w", s.bucket, path.Join(s.prefix, tmpName), err)
+	}
+	return pf, tmpName, nil
+}
+
+func (s *s3Store) publish(tmpName, finalName string) error {
+	ctx := context.Background()
+	src := fmt.Sprintf("%s/%s", s.bucket, path.Join(s.prefix, tmpName))
+	dstKey := path.Join(s.prefix, finalName)
+
+	uploader := manager.NewUploader(s.client)
+	_, err := uploader.S3.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:     aws.String(s.bucket),
+		CopySource: aws.String(src),
+		Key:        aws.String(dstKey),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish s3://%s/%s: %w", s.bucket, dstKey, err)
+	}
+
+	_, err = s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(path.Join(s.prefix, tmpName)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove temporary object s3://%s/%s: %w", s.bucket, path.Join(s.prefix, tmpName), err)
+	}
+	return nil
+}
@@ -0,0 +1,342 @@
+// Package main provides a standalone CLI for the postgres-cdc-sink
+// processor.
+//
+// postgres-cdc-sink stores events in PostgreSQL like postgres-sink, but in
+// the same transaction also appends to a transactional outbox table
+// (events_outbox). A second goroutine tails that outbox and re-emits
+// committed events, either as newline-delimited JSON on stdout or over a
+// gRPC StreamEvents server, giving downstream consumers exactly-once
+// delivery of committed events even if this sink (or the upstream origin)
+// restarts mid-stream.
+//
+// Usage:
+//
+//	# Ingest and tail to stdout
+//	token-transfer --start-ledger 60200000 --end-ledger 60200100 | \
+//	  postgres-cdc-sink --dsn "$POSTGRES_DSN" --resume-from
+//
+//	# Ingest and serve the outbox over gRPC instead
+//	postgres-cdc-sink --dsn "$POSTGRES_DSN" --stream-addr :9200
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+
+	"github.com/withObsrvr/nebu-processor-registry/pkg/batch"
+	"github.com/withObsrvr/nebu-processor-registry/pkg/checkpoint"
+	pb "github.com/withObsrvr/nebu-processor-registry/processors/postgres-cdc-sink/proto"
+	"github.com/withObsrvr/nebu/pkg/processor/cli"
+	"github.com/withObsrvr/nebu/pkg/toid"
+)
+
+const version = "0.1.0"
+
+var (
+	dsn         string
+	tableName   string
+	outboxTable string
+	batchSize   int
+	resumeFrom  bool
+
+	streamAddr   string
+	pollInterval time.Duration
+	ckptOpts     *checkpoint.Options
+
+	db      *sql.DB
+	batcher *batch.Batcher
+	ctx     context.Context
+	cancel  context.CancelFunc
+
+	maxCommittedID int64
+	tailStop       chan struct{}
+)
+
+func main() {
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	setupCleanup()
+
+	config := cli.SinkConfig{
+		Name:        "postgres-cdc-sink",
+		Description: "Store events in PostgreSQL with a transactional outbox for exactly-once CDC",
+		Version:     version,
+	}
+
+	cli.RunSinkCLI(config, processEvent, addFlags)
+
+	cleanup()
+}
+
+func setupCleanup() {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-c
+		fmt.Fprintln(os.Stderr, "\nReceived shutdown signal, flushing...")
+		cleanup()
+		os.Exit(0)
+	}()
+}
+
+func cleanup() {
+	if batcher != nil {
+		if err := batcher.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "postgres-cdc-sink: error flushing final batch: %v\n", err)
+		}
+	}
+	if tailStop != nil {
+		close(tailStop)
+	}
+	cancel()
+	if db != nil {
+		db.Close()
+	}
+}
+
+func addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&dsn, "dsn", getEnvOrDefault("POSTGRES_DSN", ""),
+		"PostgreSQL connection string (or set POSTGRES_DSN env)")
+	cmd.Flags().StringVar(&tableName, "table", "events", "Table name for storing events")
+	cmd.Flags().StringVar(&outboxTable, "outbox-table", "events_outbox", "Transactional outbox table name")
+	cmd.Flags().IntVar(&batchSize, "batch-size", 1000, "Number of events to batch before a commit")
+	cmd.Flags().BoolVar(&resumeFrom, "resume-from", false,
+		"Look up MAX(id) already committed to --table on startup and skip incoming events at or below it, so a restart doesn't re-insert the in-flight COPY batch")
+
+	cmd.Flags().StringVar(&streamAddr, "stream-addr", "",
+		"Address for the StreamEvents gRPC server that re-emits tailed outbox rows (empty: write NDJSON to stdout instead)")
+	cmd.Flags().DurationVar(&pollInterval, "poll-interval", time.Second, "How often to poll the outbox table for new rows")
+	ckptOpts = checkpoint.AddFlags(cmd)
+
+	cmd.MarkFlagRequired("dsn")
+}
+
+// processEvent handles each incoming event: lazy-connects, resolves an id
+// the same way postgres-sink does to honor --resume-from, and hands it to
+// the batcher shared by the events and outbox tables.
+func processEvent(event map[string]interface{}) error {
+	if db == nil {
+		if err := connect(); err != nil {
+			return err
+		}
+		if err := ensureTables(); err != nil {
+			return err
+		}
+		if resumeFrom {
+			if err := loadMaxCommittedID(); err != nil {
+				return err
+			}
+		}
+		batcher = batch.New(batch.Config{MaxSize: batchSize, FlushInterval: time.Second}, flushBatch)
+		if err := startTailer(); err != nil {
+			return err
+		}
+	}
+
+	if resumeFrom {
+		id, err := resolveID(event)
+		if err != nil {
+			return err
+		}
+		if id <= maxCommittedID {
+			return nil
+		}
+	}
+
+	return batcher.Add(event)
+}
+
+func resolveID(event map[string]interface{}) (int64, error) {
+	if toidVal, ok := event["toid"]; ok {
+		return toNumber(toidVal)
+	}
+	if idVal, ok := event["id"]; ok {
+		return toNumber(idVal)
+	}
+	return toid.FromEvent(event)
+}
+
+func toNumber(v interface{}) (int64, error) {
+	switch n := v.(type) {
+	case float64:
+		return int64(n), nil
+	case int64:
+		return n, nil
+	case int:
+		return int64(n), nil
+	default:
+		return 0, fmt.Errorf("invalid id type: %T", v)
+	}
+}
+
+func connect() error {
+	var err error
+	db, err = sql.Open("postgres", dsn)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	db.SetMaxOpenConns(25)
+	db.SetMaxIdleConns(5)
+	db.SetConnMaxLifetime(5 * time.Minute)
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	return nil
+}
+
+func ensureTables() error {
+	query := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			id BIGINT PRIMARY KEY,
+			data JSONB NOT NULL,
+			created_at TIMESTAMPTZ DEFAULT NOW()
+		)
+	`, tableName)
+	if _, err := db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to create table: %w", err)
+	}
+
+	outbox := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			id BIGINT PRIMARY KEY,
+			payload JSONB NOT NULL,
+			published_at TIMESTAMPTZ DEFAULT NOW()
+		)
+	`, outboxTable)
+	if _, err := db.ExecContext(ctx, outbox); err != nil {
+		return fmt.Errorf("failed to create outbox table: %w", err)
+	}
+	return nil
+}
+
+// loadMaxCommittedID looks up MAX(id) in --table for --resume-from.
+func loadMaxCommittedID() error {
+	query := fmt.Sprintf(`SELECT COALESCE(MAX(id), 0) FROM %s`, tableName)
+	return db.QueryRowContext(ctx, query).Scan(&maxCommittedID)
+}
+
+// flushBatch writes a batch to both the events table and the transactional
+// outbox table in one transaction, so a row is only ever visible to the
+// tailer once it's durably committed alongside the event it describes.
+// It's the batch.FlushFunc the package-level batcher calls; batcher itself
+// guards concurrent access to the pending batch, so this runs free of any
+// data race between processEvent and the flush ticker.
+func flushBatch(events []map[string]interface{}) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	eventsStmt, err := tx.PrepareContext(ctx, fmt.Sprintf(
+		`INSERT INTO %s (id, data) VALUES ($1, $2) ON CONFLICT (id) DO NOTHING`, tableName))
+	if err != nil {
+		return fmt.Errorf("failed to prepare events statement: %w", err)
+	}
+	defer eventsStmt.Close()
+
+	outboxStmt, err := tx.PrepareContext(ctx, fmt.Sprintf(
+		`INSERT INTO %s (id, payload) VALUES ($1, $2) ON CONFLICT (id) DO NOTHING`, outboxTable))
+	if err != nil {
+		return fmt.Errorf("failed to prepare outbox statement: %w", err)
+	}
+	defer outboxStmt.Close()
+
+	for _, event := range events {
+		id, err := resolveID(event)
+		if err != nil {
+			return fmt.Errorf("failed to resolve id: %w", err)
+		}
+		data, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event: %w", err)
+		}
+		if _, err := eventsStmt.ExecContext(ctx, id, data); err != nil {
+			return fmt.Errorf("failed to insert event %d: %w", id, err)
+		}
+		if _, err := outboxStmt.ExecContext(ctx, id, data); err != nil {
+			return fmt.Errorf("failed to insert outbox row %d: %w", id, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// startTailer starts the outbox tailer: a gRPC StreamEvents server if
+// --stream-addr is set, otherwise a background goroutine writing NDJSON to
+// stdout. The stdout tailer resumes from the last cursor in
+// --checkpoint-store (if configured), so restarting this sink doesn't
+// re-emit already published rows downstream; in gRPC mode the store is not
+// read, and each StreamEvents call starts from the cursor in its request.
+func startTailer() error {
+	tailStop = make(chan struct{})
+
+	var store checkpoint.Store
+	if ckptOpts.StoreDSN != "" {
+		s, err := checkpoint.Open(ckptOpts.StoreDSN)
+		if err != nil {
+			return fmt.Errorf("failed to open checkpoint store: %w", err)
+		}
+		store = s
+	}
+
+	if streamAddr != "" {
+		lis, err := net.Listen("tcp", streamAddr)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", streamAddr, err)
+		}
+		grpcServer := grpc.NewServer()
+		pb.RegisterPostgresCDCServiceServer(grpcServer, NewCDCServer(db, outboxTable, pollInterval))
+		reflection.Register(grpcServer)
+		go func() {
+			fmt.Fprintf(os.Stderr, "postgres-cdc-sink: streaming outbox on %s\n", streamAddr)
+			if err := grpcServer.Serve(lis); err != nil {
+				fmt.Fprintf(os.Stderr, "postgres-cdc-sink: gRPC server error: %v\n", err)
+			}
+		}()
+		return nil
+	}
+
+	start, err := loadCursor(store)
+	if err != nil {
+		return fmt.Errorf("failed to load outbox cursor: %w", err)
+	}
+
+	go func() {
+		err := tailOutbox(ctx, db, outboxTable, start, pollInterval, tailStop, func(id int64, payload []byte) error {
+			if _, err := os.Stdout.Write(append(payload, '\n')); err != nil {
+				return err
+			}
+			return saveCursor(store, id)
+		})
+		if err != nil && ctx.Err() == nil {
+			fmt.Fprintf(os.Stderr, "postgres-cdc-sink: outbox tailer stopped: %v\n", err)
+		}
+	}()
+	return nil
+}
+
+func getEnvOrDefault(key, defaultValue string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return defaultValue
+}
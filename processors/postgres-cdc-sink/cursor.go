@@ -0,0 +1,32 @@
+package main
+
+import "github.com/withObsrvr/nebu-processor-registry/pkg/checkpoint"
+
+// outboxShard disambiguates the outbox tailer's cursor from any checkpoint
+// a separate origin might be keeping in the same --checkpoint-store.
+const outboxShard = "outbox"
+
+// loadCursor returns the last outbox id the tailer published, or 0 if
+// --checkpoint-store wasn't set or nothing has been published yet.
+// checkpoint.Store tracks a uint32 ledger sequence; that's a good enough
+// fit for an outbox id cursor since both are monotonically increasing
+// counters, and it means the tailer reuses the same Store
+// implementations (file/sqlite/redis/nats) as every origin in this repo
+// instead of inventing its own persistence format.
+func loadCursor(store checkpoint.Store) (int64, error) {
+	if store == nil {
+		return 0, nil
+	}
+	last, err := store.Load("postgres-cdc-sink", outboxShard)
+	if err != nil {
+		return 0, err
+	}
+	return int64(last), nil
+}
+
+func saveCursor(store checkpoint.Store, id int64) error {
+	if store == nil {
+		return nil
+	}
+	return store.Save("postgres-cdc-sink", outboxShard, uint32(id))
+}
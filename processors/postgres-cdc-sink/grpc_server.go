@@ -0,0 +1,38 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	pb "github.com/withObsrvr/nebu-processor-registry/processors/postgres-cdc-sink/proto"
+)
+
+// CDCServer implements the gRPC side of the outbox tailer: each
+// StreamEvents call runs its own independent tailOutbox poll starting from
+// the caller's requested cursor, so concurrent subscribers don't block
+// each other and each gets exactly-once delivery of every committed
+// outbox row from that point on.
+type CDCServer struct {
+	pb.UnimplementedPostgresCDCServiceServer
+	db           *sql.DB
+	outboxTable  string
+	pollInterval time.Duration
+}
+
+// NewCDCServer creates a CDCServer tailing outboxTable on db.
+func NewCDCServer(db *sql.DB, outboxTable string, pollInterval time.Duration) *CDCServer {
+	return &CDCServer{db: db, outboxTable: outboxTable, pollInterval: pollInterval}
+}
+
+// StreamEvents tails the outbox table starting from req.AfterId (0 to
+// start from the beginning) and forwards each row as it's committed.
+func (s *CDCServer) StreamEvents(req *pb.StreamRequest, stream pb.PostgresCDCService_StreamEventsServer) error {
+	ctx := stream.Context()
+	return tailOutbox(ctx, s.db, s.outboxTable, req.GetAfterId(), s.pollInterval, ctx.Done(), func(id int64, payload []byte) error {
+		if err := stream.Send(&pb.OutboxEvent{Id: id, PayloadJson: payload}); err != nil {
+			return fmt.Errorf("failed to send outbox event %d: %w", id, err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Dead-letter-queue destinations for --dlq-mode. Empty (the default)
+// preserves the original all-or-nothing behavior: the whole batch's error
+// is returned and the pipeline stops instead of silently dropping rows.
+const (
+	dlqModeTable  = "table"
+	dlqModeFile   = "file"
+	dlqModeStderr = "stderr"
+	dlqModeDrop   = "drop"
+)
+
+// transientPQCodes are pq error codes worth retrying the whole batch for
+// before degrading to per-row recovery: serialization failures and
+// deadlocks are expected under concurrent writers, and a dropped
+// connection is usually back within a retry or two.
+var transientPQCodes = map[string]bool{
+	"40001": true, // serialization_failure
+	"40P01": true, // deadlock_detected
+	"08006": true, // connection_failure
+}
+
+var (
+	dlqMode    string
+	dlqPath    string
+	maxRetries int
+
+	dlqFile       *os.File
+	dlqFileWriter *bufio.Writer
+)
+
+type rejectedEvent struct {
+	id       int64
+	data     []byte
+	errMsg   string
+	failedAt time.Time
+}
+
+// isTransientPQError reports whether err is a pq error code worth retrying
+// the whole batch for rather than immediately degrading to per-row
+// recovery.
+func isTransientPQError(err error) bool {
+	pqErr, ok := err.(*pq.Error)
+	if !ok {
+		return false
+	}
+	return transientPQCodes[string(pqErr.Code)]
+}
+
+// flushBatchFast runs the whole batch through one prepared-statement
+// transaction, same as the original all-or-nothing flushBatch. This stays
+// the common path so throughput doesn't pay the per-row savepoint cost
+// unless a row actually fails.
+func flushBatchFast(events []batchEvent) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, getUpsertQuery())
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, evt := range events {
+		if _, err := stmt.ExecContext(ctx, evt.id, evt.eventType, evt.data); err != nil {
+			return fmt.Errorf("failed to insert event %d: %w", evt.id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// flushBatchPerRow re-inserts events one row at a time inside a single
+// transaction, using a savepoint around each row so one bad row (a
+// constraint violation, oversized JSON, a stale TOID in --conflict=update
+// mode) rolls back only itself instead of the whole batch. Rejected rows
+// go to the configured DLQ destination.
+func flushBatchPerRow(events []batchEvent) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := getUpsertQuery()
+	for _, evt := range events {
+		if _, err := tx.ExecContext(ctx, "SAVEPOINT row_recovery"); err != nil {
+			return fmt.Errorf("failed to create savepoint: %w", err)
+		}
+
+		_, execErr := tx.ExecContext(ctx, query, evt.id, evt.eventType, evt.data)
+		if execErr == nil {
+			if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT row_recovery"); err != nil {
+				return fmt.Errorf("failed to release savepoint: %w", err)
+			}
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT row_recovery"); err != nil {
+			return fmt.Errorf("failed to roll back savepoint: %w", err)
+		}
+		if err := writeDLQ(rejectedEvent{
+			id:       evt.id,
+			data:     evt.data,
+			errMsg:   execErr.Error(),
+			failedAt: time.Now(),
+		}); err != nil {
+			return fmt.Errorf("failed to write rejected event %d to DLQ: %w", evt.id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// writeDLQ dispatches a rejected event to the configured --dlq-mode
+// destination.
+func writeDLQ(rej rejectedEvent) error {
+	switch dlqMode {
+	case dlqModeTable:
+		return writeDLQTable(rej)
+	case dlqModeFile:
+		return writeDLQFile(rej)
+	case dlqModeStderr:
+		fmt.Fprintf(os.Stderr, "postgres-sink: DLQ id=%d error=%q\n", rej.id, rej.errMsg)
+		return nil
+	case dlqModeDrop, "":
+		return nil
+	default:
+		return fmt.Errorf("unknown --dlq-mode %q", dlqMode)
+	}
+}
+
+// ensureDLQTable creates the sibling "<table>_dlq" table on first use.
+func ensureDLQTable() error {
+	query := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s_dlq (
+			id BIGINT NOT NULL,
+			data JSONB NOT NULL,
+			error TEXT NOT NULL,
+			failed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)
+	`, tableName)
+	if _, err := db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to create DLQ table: %w", err)
+	}
+	return nil
+}
+
+var dlqTableReady bool
+
+func writeDLQTable(rej rejectedEvent) error {
+	if !dlqTableReady {
+		if err := ensureDLQTable(); err != nil {
+			return err
+		}
+		dlqTableReady = true
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s_dlq (id, data, error, failed_at) VALUES ($1, $2, $3, $4)`, tableName)
+	_, err := db.ExecContext(ctx, query, rej.id, rej.data, rej.errMsg, rej.failedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert into DLQ table: %w", err)
+	}
+	return nil
+}
+
+func writeDLQFile(rej rejectedEvent) error {
+	if dlqFileWriter == nil {
+		f, err := os.OpenFile(dlqPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to open DLQ file %s: %w", dlqPath, err)
+		}
+		dlqFile = f
+		dlqFileWriter = bufio.NewWriter(f)
+	}
+
+	line, err := json.Marshal(map[string]interface{}{
+		"id":        rej.id,
+		"data":      json.RawMessage(rej.data),
+		"error":     rej.errMsg,
+		"failed_at": rej.failedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal DLQ entry: %w", err)
+	}
+	if _, err := dlqFileWriter.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write DLQ entry: %w", err)
+	}
+	return dlqFileWriter.Flush()
+}
+
+func closeDLQFile() {
+	if dlqFile != nil {
+		dlqFile.Close()
+	}
+}
@@ -16,6 +16,8 @@ import (
 
 	_ "github.com/lib/pq"
 	"github.com/spf13/cobra"
+	"github.com/withObsrvr/nebu-processor-registry/pkg/batch"
+	"github.com/withObsrvr/nebu-processor-registry/pkg/extract"
 	"github.com/withObsrvr/nebu/pkg/processor/cli"
 	"github.com/withObsrvr/nebu/pkg/toid"
 )
@@ -33,12 +35,15 @@ var (
 	// Conflict resolution
 	conflictMode string // "ignore" or "update"
 
+	// Event type extraction
+	eventTypeExpr []string
+	eventTypeExt  *extract.Extractor
+
 	// State
-	db          *sql.DB
-	batch       []batchEvent
-	ctx         context.Context
-	cancel      context.CancelFunc
-	flushTicker *time.Ticker
+	db      *sql.DB
+	batcher *batch.Batcher
+	ctx     context.Context
+	cancel  context.CancelFunc
 )
 
 type batchEvent struct {
@@ -79,14 +84,9 @@ func setupCleanup() {
 
 // cleanup ensures database connection is properly closed and batch is flushed
 func cleanup() {
-	// Stop the ticker first
-	if flushTicker != nil {
-		flushTicker.Stop()
-	}
-
 	// Flush any pending events BEFORE canceling context
-	if db != nil && len(batch) > 0 {
-		if err := flushBatch(); err != nil {
+	if batcher != nil {
+		if err := batcher.Close(); err != nil {
 			fmt.Fprintf(os.Stderr, "Error flushing final batch: %v\n", err)
 		}
 	}
@@ -98,6 +98,8 @@ func cleanup() {
 	if db != nil {
 		db.Close()
 	}
+
+	closeDLQFile()
 }
 
 // addFlags adds custom flags to the command
@@ -110,6 +112,15 @@ func addFlags(cmd *cobra.Command) {
 		"Number of events to batch before COPY")
 	cmd.Flags().StringVar(&conflictMode, "conflict", "ignore",
 		"Conflict resolution: 'ignore' (DO NOTHING) or 'update' (DO UPDATE)")
+	cmd.Flags().StringArrayVar(&eventTypeExpr, "event-type-expr", nil,
+		`jq expression selecting the event's type, repeatable and tried in order (default: the built-in event_type/eventType/functionName/oneof/type ladder)`)
+
+	cmd.Flags().StringVar(&dlqMode, "dlq-mode", "",
+		"Per-row error isolation destination: table|file|stderr|drop (empty disables DLQ: a bad row fails the whole batch)")
+	cmd.Flags().StringVar(&dlqPath, "dlq-path", "dlq.jsonl",
+		"File path for --dlq-mode=file")
+	cmd.Flags().IntVar(&maxRetries, "max-retries", 3,
+		"Retries for the whole batch on a transient error (serialization failure, deadlock, connection reset) before degrading to per-row recovery")
 
 	cmd.MarkFlagRequired("dsn")
 }
@@ -124,118 +135,97 @@ func processEvent(event map[string]interface{}) error {
 		if err := ensureTable(); err != nil {
 			return err
 		}
-		startFlushTicker()
+		batcher = batch.New(batch.Config{MaxSize: batchSize, FlushInterval: time.Second}, flushBatch)
 	}
 
-	// Generate or extract TOID
-	var id int64
-	var err error
+	return batcher.Add(event)
+}
 
-	// Check if event already has a pre-calculated TOID
+// resolveID returns the event's id: a pre-calculated "toid" or "id" field if
+// present, otherwise one auto-generated from meta fields.
+func resolveID(event map[string]interface{}) (int64, error) {
 	if toidVal, ok := event["toid"]; ok {
-		switch v := toidVal.(type) {
-		case float64:
-			id = int64(v)
-		case int64:
-			id = v
-		case int:
-			id = int64(v)
-		default:
-			return fmt.Errorf("invalid toid type: %T", toidVal)
-		}
-	} else if idVal, ok := event["id"]; ok {
-		// Also support "id" field
-		switch v := idVal.(type) {
-		case float64:
-			id = int64(v)
-		case int64:
-			id = v
-		case int:
-			id = int64(v)
-		default:
-			return fmt.Errorf("invalid id type: %T", idVal)
-		}
-	} else {
-		// Auto-generate TOID from meta fields
-		id, err = toid.FromEvent(event)
-		if err != nil {
-			return fmt.Errorf("failed to generate TOID: %w", err)
-		}
+		return toNumber(toidVal)
 	}
-
-	// Extract event type if present
-	eventType := extractEventType(event)
-
-	// Marshal event to JSON
-	data, err := json.Marshal(event)
+	if idVal, ok := event["id"]; ok {
+		return toNumber(idVal)
+	}
+	id, err := toid.FromEvent(event)
 	if err != nil {
-		return fmt.Errorf("failed to marshal event: %w", err)
+		return 0, fmt.Errorf("failed to generate TOID: %w", err)
 	}
+	return id, nil
+}
 
-	// Add to batch
-	batch = append(batch, batchEvent{
-		id:        id,
-		eventType: eventType,
-		data:      data,
-	})
-
-	// Flush if batch is full
-	if len(batch) >= batchSize {
-		return flushBatch()
+func toNumber(v interface{}) (int64, error) {
+	switch n := v.(type) {
+	case float64:
+		return int64(n), nil
+	case int64:
+		return n, nil
+	case int:
+		return int64(n), nil
+	default:
+		return 0, fmt.Errorf("invalid id type: %T", v)
 	}
-
-	return nil
 }
 
-// extractEventType extracts the event type from an event.
-// Supports multiple formats:
-//   - Custom jq: "event_type" or "function_name" field
-//   - contract-events: "eventType" field (e.g., "transfer", "fee")
-//   - contract-invocation: "functionName" field (e.g., "work", "transfer")
-//   - protobuf oneof: field name indicates type (e.g., has "transfer" field)
-//   - simple: "type" field (e.g., {"type": "transfer"})
-func extractEventType(event map[string]interface{}) *string {
-	// Try custom jq "event_type" field first (snake_case convention)
-	if t, ok := event["event_type"].(string); ok && t != "" && t != "unknown" {
-		result := t
-		return &result
+// toBatchEvent resolves an event's id and event type and marshals it to
+// JSON, ready for flushBatchFast/flushBatchPerRow.
+func toBatchEvent(event map[string]interface{}) (batchEvent, error) {
+	id, err := resolveID(event)
+	if err != nil {
+		return batchEvent{}, err
 	}
 
-	// Try contract-events "eventType" field (camelCase)
-	if t, ok := event["eventType"].(string); ok && t != "" && t != "unknown" {
-		result := t
-		return &result
+	data, err := json.Marshal(event)
+	if err != nil {
+		return batchEvent{}, fmt.Errorf("failed to marshal event: %w", err)
 	}
 
-	// Try contract-invocation "functionName" field
-	if t, ok := event["functionName"].(string); ok && t != "" {
-		result := t
-		return &result
-	}
+	return batchEvent{
+		id:        id,
+		eventType: extractEventType(event),
+		data:      data,
+	}, nil
+}
 
-	// Try custom jq "function_name" field (snake_case)
-	if t, ok := event["function_name"].(string); ok && t != "" {
-		result := t
-		return &result
-	}
+// defaultEventTypeExprs reproduces the original hard-coded ladder as jq
+// expressions, so a run without --event-type-expr behaves exactly as
+// before.
+var defaultEventTypeExprs = []string{
+	`.event_type | select(. != null and . != "" and . != "unknown")`,
+	`.eventType | select(. != null and . != "" and . != "unknown")`,
+	`.functionName | select(. != null and . != "")`,
+	`.function_name | select(. != null and . != "")`,
+	`if .transfer then "transfer" elif .mint then "mint" elif .burn then "burn" elif .clawback then "clawback" elif .fee then "fee" elif .payment then "payment" elif .invoke then "invoke" else empty end`,
+	`.type | select(. != "CONTRACT" and . != "SYSTEM" and . != "DIAGNOSTIC")`,
+}
 
-	// Try protobuf oneof fields (token-transfer, etc.)
-	// Check for common event type fields
-	oneofFields := []string{"transfer", "mint", "burn", "clawback", "fee", "payment", "invoke"}
-	for _, field := range oneofFields {
-		if _, exists := event[field]; exists {
-			result := field
-			return &result
+// extractEventType extracts the event type from an event by running
+// --event-type-expr (or the default ladder above, covering custom jq
+// event_type/function_name fields, contract-events/contract-invocation
+// camelCase fields, protobuf oneofs, and a plain "type" field) and
+// returning the first non-empty match.
+func extractEventType(event map[string]interface{}) *string {
+	if eventTypeExt == nil {
+		exprs := eventTypeExpr
+		if len(exprs) == 0 {
+			exprs = defaultEventTypeExprs
 		}
+		ext, err := extract.New(exprs)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "postgres-sink: invalid --event-type-expr: %v\n", err)
+			os.Exit(1)
+		}
+		eventTypeExt = ext
 	}
 
-	// Fall back to simple "type" field (but skip enum values like "CONTRACT")
-	if t, ok := event["type"].(string); ok && t != "CONTRACT" && t != "SYSTEM" && t != "DIAGNOSTIC" {
-		result := t
-		return &result
+	t, ok := eventTypeExt.ExtractString(event)
+	if !ok {
+		return nil
 	}
-
-	return nil
+	return &t
 }
 
 // connect establishes connection to PostgreSQL
@@ -291,42 +281,48 @@ func ensureTable() error {
 	return nil
 }
 
-// flushBatch writes the current batch to PostgreSQL using COPY
-func flushBatch() error {
-	if len(batch) == 0 {
+// flushBatch resolves each raw event's id and event type and writes the
+// batch to PostgreSQL. The fast path is a single prepared-statement
+// transaction covering the whole batch; it only degrades to per-row
+// savepoint recovery (and a --dlq-mode destination for the rows that
+// actually fail) after that fast path errors out, so a healthy batch never
+// pays the per-row cost. It's the batch.FlushFunc the package-level batcher
+// calls; batcher itself guards concurrent access to the pending batch and
+// drops it from pending before invoking this, so a retry after a partial
+// per-row failure can't resubmit and duplicate rows already written to the
+// DLQ.
+func flushBatch(rawEvents []map[string]interface{}) error {
+	if len(rawEvents) == 0 {
 		return nil
 	}
 
-	tx, err := db.BeginTx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+	events := make([]batchEvent, 0, len(rawEvents))
+	for _, raw := range rawEvents {
+		evt, err := toBatchEvent(raw)
+		if err != nil {
+			return err
+		}
+		events = append(events, evt)
 	}
-	defer tx.Rollback()
 
-	// Prepare COPY statement
-	stmt, err := tx.PrepareContext(ctx, getUpsertQuery())
-	if err != nil {
-		return fmt.Errorf("failed to prepare statement: %w", err)
+	err := flushBatchFast(events)
+	if err == nil {
+		return nil
 	}
-	defer stmt.Close()
 
-	// Insert each event in the batch
-	for _, evt := range batch {
-		_, err := stmt.ExecContext(ctx, evt.id, evt.eventType, evt.data)
-		if err != nil {
-			return fmt.Errorf("failed to insert event: %w", err)
+	for attempt := 1; isTransientPQError(err) && attempt <= maxRetries; attempt++ {
+		time.Sleep(time.Duration(attempt*attempt) * 100 * time.Millisecond)
+		err = flushBatchFast(events)
+		if err == nil {
+			return nil
 		}
 	}
 
-	// Commit transaction
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+	if dlqMode == "" {
+		return err
 	}
 
-	// Clear batch
-	batch = batch[:0]
-
-	return nil
+	return flushBatchPerRow(events)
 }
 
 // getUpsertQuery returns the appropriate INSERT query based on conflict mode
@@ -351,25 +347,6 @@ func getUpsertQuery() string {
 	}
 }
 
-// startFlushTicker starts a ticker to flush batches periodically
-func startFlushTicker() {
-	flushTicker = time.NewTicker(1 * time.Second)
-	go func() {
-		for {
-			select {
-			case <-flushTicker.C:
-				if len(batch) > 0 {
-					if err := flushBatch(); err != nil {
-						fmt.Fprintf(os.Stderr, "Error flushing batch: %v\n", err)
-					}
-				}
-			case <-ctx.Done():
-				return
-			}
-		}
-	}()
-}
-
 // getEnvOrDefault gets environment variable or returns default
 func getEnvOrDefault(key, defaultValue string) string {
 	if val := os.Getenv(key); val != "" {
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveID(t *testing.T) {
+	id, err := resolveID(map[string]interface{}{"toid": float64(42)})
+	require.NoError(t, err)
+	assert.EqualValues(t, 42, id)
+
+	id, err = resolveID(map[string]interface{}{"id": float64(7)})
+	require.NoError(t, err)
+	assert.EqualValues(t, 7, id)
+
+	_, err = resolveID(map[string]interface{}{"toid": "not-a-number"})
+	assert.Error(t, err)
+}
+
+func TestToBatchEvent(t *testing.T) {
+	eventTypeExt = nil
+	eventTypeExpr = nil
+
+	evt, err := toBatchEvent(map[string]interface{}{"toid": float64(1), "event_type": "transfer"})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, evt.id)
+	require.NotNil(t, evt.eventType)
+	assert.Equal(t, "transfer", *evt.eventType)
+	assert.Contains(t, string(evt.data), `"toid":1`)
+}
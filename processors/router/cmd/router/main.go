@@ -0,0 +1,79 @@
+// Package main provides a general-purpose routing sink: it reads events
+// from stdin and dispatches each to one of several destinations based on a
+// `--route-file` match table, so multi-tenant pipelines can be composed
+// without writing a custom sink per destination.
+//
+// Usage:
+//
+//	token-transfer --start-ledger 60200000 --end-ledger 60200100 | \
+//	  router --route-file routes.yaml
+//
+// routes.yaml:
+//
+//	nats:
+//	  url: nats://localhost:4222
+//	rules:
+//	  - when: 'type=="transfer" && asset.code=="USDC"'
+//	    to: "nats://stellar.usdc.{to}"
+//	  - when: 'type=="fee"'
+//	    to: "file:///var/log/fees.jsonl"
+//	  - to: "stdout://"   # catch-all (no `when`) must come last
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/withObsrvr/nebu-processor-registry/pkg/metrics"
+	"github.com/withObsrvr/nebu-processor-registry/pkg/routing"
+	"github.com/withObsrvr/nebu/pkg/processor/cli"
+)
+
+var version = "0.1.0"
+
+var (
+	routeFile string
+	strict    bool
+
+	router *routing.Router
+)
+
+func main() {
+	config := cli.SinkConfig{
+		Name:        "router",
+		Description: "Dispatch events to destinations based on a --route-file match table",
+		Version:     version,
+	}
+
+	cli.RunSinkCLI(config, routeEvent, addFlags)
+
+	if router != nil {
+		router.Close()
+	}
+}
+
+func addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&routeFile, "route-file", "", "Path to the YAML route file (required)")
+	cmd.Flags().BoolVar(&strict, "strict", false, "Fail on missing template variables (default: use '_unknown')")
+	cmd.MarkFlagRequired("route-file")
+}
+
+func routeEvent(event map[string]interface{}) error {
+	if router == nil {
+		cfg, err := routing.LoadConfig(routeFile)
+		if err != nil {
+			return err
+		}
+
+		reg := metrics.NewRegistry("router")
+		routed := reg.NATSPublishCounter() // labels: subject/status; reused here as destination/status
+
+		r, err := routing.New(cfg, strict, routed, reg.FilterDrops)
+		if err != nil {
+			return fmt.Errorf("invalid route file %s: %w", routeFile, err)
+		}
+		router = r
+	}
+
+	return router.Route(event)
+}
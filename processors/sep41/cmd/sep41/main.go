@@ -0,0 +1,33 @@
+// Package main provides a standalone CLI for the sep41 transform processor.
+//
+// This processor decodes contract-events' generic ContractEvent shape into
+// strongly-typed SEP-41 token events (transfer, mint, burn, clawback,
+// set_authorized, set_admin, approve). Events that don't match a known
+// SEP-41 shape pass through unchanged, so non-conforming tokens are still
+// surfaced rather than dropped.
+//
+// Usage:
+//
+//	contract-events --start-ledger 60200000 --end-ledger 60200100 | sep41
+//
+//	contract-events --start-ledger 60200000 --end-ledger 60200100 | \
+//	  sep41 | \
+//	  json-file-sink --out token-events.jsonl
+package main
+
+import (
+	"github.com/withObsrvr/nebu-processor-registry/processors/sep41"
+	"github.com/withObsrvr/nebu/pkg/processor/cli"
+)
+
+var version = "0.1.0"
+
+func main() {
+	config := cli.TransformConfig{
+		Name:        "sep41",
+		Description: "Decode contract events into strongly-typed SEP-41 token events",
+		Version:     version,
+	}
+
+	cli.RunTransformCLI(config, sep41.Decode, nil)
+}
@@ -0,0 +1,175 @@
+// Package sep41 decodes contract-events' generic ContractEvent shape into
+// strongly-typed SEP-41 token events (transfer, mint, burn, clawback,
+// set_authorized, set_admin, approve), so downstream consumers can read
+// event["transfer"]["to"] instead of walking TopicDecoded/DataDecoded by
+// position. Non-conforming events (wrong arity, unexpected ScVal shapes, or
+// a symbol SEP-41 doesn't define) pass through unchanged.
+package sep41
+
+// shape describes one SEP-41 event's topic/data layout: params holds every
+// named field in declaration order, and topicCount says how many of the
+// leading params (after the discriminant symbol, already consumed) are
+// carried as indexed topics rather than in the data payload.
+type shape struct {
+	params     []string
+	topicCount int
+}
+
+// shapes mirrors the SEP-41 interface's canonical event signatures.
+var shapes = map[string]shape{
+	"transfer":       {params: []string{"from", "to", "amount"}, topicCount: 2},
+	"mint":           {params: []string{"admin", "to", "amount"}, topicCount: 2},
+	"burn":           {params: []string{"from", "amount"}, topicCount: 1},
+	"clawback":       {params: []string{"admin", "from", "amount"}, topicCount: 2},
+	"set_authorized": {params: []string{"admin", "id", "authorize"}, topicCount: 2},
+	"set_admin":      {params: []string{"admin", "new_admin"}, topicCount: 1},
+	"approve":        {params: []string{"from", "spender", "amount", "live_until_ledger"}, topicCount: 2},
+}
+
+// Decode recognizes a SEP-41 event shape in a protojson-decoded
+// ContractEvent and, if it matches, returns {"meta": {...}, "event": name,
+// name: {fields...}} in place of event. It returns event itself, unchanged,
+// when contractId/topicDecoded are missing, the first topic isn't a known
+// SEP-41 symbol, or the topic/data arity doesn't match that symbol's shape.
+func Decode(event map[string]interface{}) map[string]interface{} {
+	contractID, _ := event["contractId"].(string)
+	topics, _ := event["topicDecoded"].([]interface{})
+	if contractID == "" || len(topics) == 0 {
+		return event
+	}
+
+	symbol, ok := scSymbol(topics[0])
+	if !ok {
+		return event
+	}
+	sh, ok := shapes[symbol]
+	if !ok {
+		return event
+	}
+	if len(topics) != sh.topicCount+1 {
+		// Same event name, different arity: not the canonical SEP-41 shape.
+		return event
+	}
+
+	fields := make(map[string]interface{}, len(sh.params))
+	for i := 0; i < sh.topicCount; i++ {
+		v, ok := scGeneric(topics[i+1])
+		if !ok {
+			return event
+		}
+		fields[sh.params[i]] = v
+	}
+
+	dataFields, ok := decodeDataFields(event["dataDecoded"], sh.params[sh.topicCount:])
+	if !ok {
+		return event
+	}
+	for name, v := range dataFields {
+		fields[name] = v
+	}
+
+	return map[string]interface{}{
+		"meta":  buildMeta(event, contractID),
+		"event": symbol,
+		symbol:  fields,
+	}
+}
+
+// buildMeta propagates the ledger/tx metadata buildContractEvent already
+// assembled, plus the contract ID as the token identifier, so a SEP-41
+// event carries the same provenance a raw ContractEvent does.
+func buildMeta(event map[string]interface{}, contractID string) map[string]interface{} {
+	return map[string]interface{}{
+		"tokenId":          contractID,
+		"ledgerSequence":   event["ledgerSequence"],
+		"timestamp":        event["timestamp"],
+		"transactionHash":  event["transactionHash"],
+		"transactionIndex": event["transactionIndex"],
+		"operationIndex":   event["operationIndex"],
+		"eventIndex":       event["eventIndex"],
+		"inSuccessfulTx":   event["inSuccessfulTx"],
+	}
+}
+
+// decodeDataFields extracts names from a decoded ScVal data payload: a
+// single scalar for a one-field shape, or a vecValue of len(names) scalars
+// for a multi-field shape (approve's amount + live_until_ledger).
+func decodeDataFields(data interface{}, names []string) (map[string]interface{}, bool) {
+	if len(names) == 0 {
+		return map[string]interface{}{}, true
+	}
+
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	if len(names) == 1 {
+		v, ok := scScalar(m)
+		if !ok {
+			return nil, false
+		}
+		return map[string]interface{}{names[0]: v}, true
+	}
+
+	vec, ok := m["vecValue"].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	values, ok := vec["values"].([]interface{})
+	if !ok || len(values) != len(names) {
+		return nil, false
+	}
+
+	fields := make(map[string]interface{}, len(names))
+	for i, name := range names {
+		v, ok := scScalar(values[i])
+		if !ok {
+			return nil, false
+		}
+		fields[name] = v
+	}
+	return fields, true
+}
+
+// scSymbol returns a decoded ScVal's symbolValue, if it has one.
+func scSymbol(v interface{}) (string, bool) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	sym, ok := m["symbolValue"].(string)
+	return sym, ok
+}
+
+// scGeneric extracts the value of whichever oneof case a topic ScVal uses.
+// SEP-41 topics are always addresses or symbols in practice.
+func scGeneric(v interface{}) (interface{}, bool) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	if addr, ok := m["addressValue"]; ok {
+		return addr, true
+	}
+	if sym, ok := m["symbolValue"]; ok {
+		return sym, true
+	}
+	return nil, false
+}
+
+// scScalar extracts the value of whichever scalar oneof case a decoded
+// ScVal data field uses (i128/u32/bool/address cover every SEP-41 data
+// field: amount, live_until_ledger, authorize, new_admin).
+func scScalar(v interface{}) (interface{}, bool) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	for _, key := range []string{"i128Value", "u32Value", "boolValue", "addressValue"} {
+		if val, ok := m[key]; ok {
+			return val, true
+		}
+	}
+	return nil, false
+}
@@ -1,7 +1,10 @@
 // Package main provides a standalone CLI for the time-window transform processor.
 //
-// This processor filters events based on time ranges using ledger sequence timestamps.
-// Stellar ledgers close approximately every 5 seconds.
+// This processor filters events based on time ranges, resolving each
+// event's close time via pkg/ledgertime (meta.closeTime, then the
+// --ledgertime-cache, then a 5-second-per-ledger approximation as a last
+// resort) rather than assuming every ledger closes exactly 5 seconds
+// apart.
 //
 // Usage:
 //
@@ -19,80 +22,89 @@
 package main
 
 import (
+	"fmt"
+	"os"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/withObsrvr/nebu-processor-registry/pkg/ledgertime"
 	"github.com/withObsrvr/nebu/pkg/processor/cli"
 )
 
-var version = "0.1.0"
+var version = "0.2.0"
 
 var (
 	lastDuration string
 	startTime    int64
 	endTime      int64
-)
 
-const stellarGenesisUnix = 1436467200 // Stellar genesis timestamp (July 1, 2015)
-const ledgerCloseTime = 5             // Approximate seconds per ledger
+	ltOpts     *ledgertime.Options
+	ltResolver *ledgertime.Resolver
+)
 
 func main() {
 	config := cli.TransformConfig{
 		Name:        "time-window",
-		Description: "Filter events by time range using ledger sequence",
+		Description: "Filter events by time range using ledger close time",
 		Version:     version,
 	}
 
 	cli.RunTransformCLI(config, filterByTimeWindow, addFlags)
+
+	if ltResolver != nil {
+		ltResolver.Close()
+	}
 }
 
 func addFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&lastDuration, "last", "", "Filter for events from last duration (e.g., 1h, 24h, 7d)")
 	cmd.Flags().Int64Var(&startTime, "start", 0, "Start timestamp (Unix seconds, 0 = no limit)")
 	cmd.Flags().Int64Var(&endTime, "end", 0, "End timestamp (Unix seconds, 0 = no limit)")
+	ltOpts = ledgertime.AddFlags(cmd)
 }
 
-// filterByTimeWindow filters events based on time ranges.
-// Uses ledger_sequence to estimate event time (ledgers close ~every 5 seconds).
-func filterByTimeWindow(event map[string]interface{}) map[string]interface{} {
-	// Get meta object (protojson format)
-	meta, ok := event["meta"].(map[string]interface{})
-	if !ok {
-		return nil // No meta, filter out
+// filterByTimeWindow filters events based on time ranges. Event time comes
+// from pkg/ledgertime: meta.closeTime when present, else the
+// --ledgertime-cache, and only the 5-second approximation when neither is
+// available.
+func filterByTimeWindow(event map[string]interface{}) (map[string]interface{}, error) {
+	if ltResolver == nil {
+		r, err := ledgertime.New(ltOpts)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ledgertime options: %w", err)
+		}
+		r.OnError = func(err error) { fmt.Fprintf(os.Stderr, "time-window: %v\n", err) }
+		ltResolver = r
 	}
 
-	// Get ledger sequence from meta
-	ledgerSeq, ok := meta["ledgerSequence"].(float64)
+	eventTime, ok := ltResolver.Resolve(event)
 	if !ok {
-		return nil // No ledgerSequence, filter out
+		return nil, nil // No ledgerSequence, filter out
 	}
 
-	// Estimate event time: genesis + (ledger * 5 seconds)
-	eventTime := stellarGenesisUnix + (int64(ledgerSeq) * ledgerCloseTime)
-
 	// Check --last duration
 	if lastDuration != "" {
 		duration, err := time.ParseDuration(lastDuration)
 		if err != nil {
-			return nil // Invalid duration, filter out
+			return nil, nil // Invalid duration, filter out
 		}
 
 		cutoffTime := time.Now().Unix() - int64(duration.Seconds())
 		if eventTime < cutoffTime {
-			return nil // Event too old
+			return nil, nil // Event too old
 		}
 	}
 
 	// Check --start timestamp
 	if startTime > 0 && eventTime < startTime {
-		return nil
+		return nil, nil
 	}
 
 	// Check --end timestamp
 	if endTime > 0 && eventTime > endTime {
-		return nil
+		return nil, nil
 	}
 
 	// Passed time window filters
-	return event
+	return event, nil
 }
@@ -0,0 +1,301 @@
+package token_transfer
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	_ "modernc.org/sqlite"
+
+	ttpb "github.com/withObsrvr/nebu-processor-registry/processors/token-transfer/proto"
+)
+
+// Archive persists every TokenTransferEvent Origin emits into a SQLite
+// database, keyed by (ledger_sequence, tx_index, op_index, event_index), so
+// a downstream consumer can replay history after downtime or page through
+// transfers via GetTransfers without maintaining its own store — the same
+// retention/pagination model Soroban RPC uses for transactions.
+type Archive struct {
+	db *sql.DB
+}
+
+// NewArchive opens (creating if needed) a SQLite database at path and
+// ensures the transfer_events table and its secondary indexes exist.
+func NewArchive(path string) (*Archive, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open transfer archive %s: %w", path, err)
+	}
+
+	const schema = `
+CREATE TABLE IF NOT EXISTS transfer_events (
+	ledger_sequence  INTEGER NOT NULL,
+	tx_index         INTEGER NOT NULL,
+	op_index         INTEGER NOT NULL,
+	event_index      INTEGER NOT NULL,
+	closed_at_unix   INTEGER NOT NULL,
+	event_type       TEXT NOT NULL,
+	tx_hash          TEXT NOT NULL,
+	asset_code       TEXT NOT NULL DEFAULT '',
+	asset_issuer     TEXT NOT NULL DEFAULT '',
+	from_addr        TEXT NOT NULL DEFAULT '',
+	to_addr          TEXT NOT NULL DEFAULT '',
+	amount           TEXT NOT NULL DEFAULT '',
+	in_successful_tx INTEGER NOT NULL,
+	PRIMARY KEY (ledger_sequence, tx_index, op_index, event_index)
+);
+CREATE INDEX IF NOT EXISTS transfer_events_asset ON transfer_events (asset_code, asset_issuer);
+CREATE INDEX IF NOT EXISTS transfer_events_from ON transfer_events (from_addr);
+CREATE INDEX IF NOT EXISTS transfer_events_to ON transfer_events (to_addr);
+`
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create transfer_events schema: %w", err)
+	}
+
+	return &Archive{db: db}, nil
+}
+
+// Close releases the underlying database handle.
+func (a *Archive) Close() error {
+	return a.db.Close()
+}
+
+// Record inserts event into the archive under the given (txIndex, opIndex,
+// eventIndex) position within its ledger, replacing any prior row at the
+// same key (a re-processed ledger overwrites rather than duplicates).
+func (a *Archive) Record(event *ttpb.TokenTransferEvent, txIndex, opIndex, eventIndex uint32) error {
+	if event == nil || event.Meta == nil {
+		return nil
+	}
+	eventType, from, to, assetCode, assetIssuer, amount := archiveFields(event)
+
+	_, err := a.db.Exec(
+		`INSERT INTO transfer_events
+			(ledger_sequence, tx_index, op_index, event_index, closed_at_unix,
+			 event_type, tx_hash, asset_code, asset_issuer, from_addr, to_addr,
+			 amount, in_successful_tx)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT (ledger_sequence, tx_index, op_index, event_index) DO UPDATE SET
+			closed_at_unix = excluded.closed_at_unix,
+			event_type = excluded.event_type,
+			tx_hash = excluded.tx_hash,
+			asset_code = excluded.asset_code,
+			asset_issuer = excluded.asset_issuer,
+			from_addr = excluded.from_addr,
+			to_addr = excluded.to_addr,
+			amount = excluded.amount,
+			in_successful_tx = excluded.in_successful_tx`,
+		event.Meta.LedgerSequence, txIndex, opIndex, eventIndex, event.Meta.ClosedAtUnix,
+		eventType, event.Meta.TxHash, assetCode, assetIssuer, from, to,
+		amount, boolToInt(event.Meta.InSuccessfulTx),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record transfer event: %w", err)
+	}
+	return nil
+}
+
+// Prune deletes every row older than retentionWindow ledgers behind
+// currentLedger. A zero retentionWindow disables pruning.
+func (a *Archive) Prune(currentLedger, retentionWindow uint32) error {
+	if retentionWindow == 0 || currentLedger <= retentionWindow {
+		return nil
+	}
+	cutoff := currentLedger - retentionWindow
+	if _, err := a.db.Exec(`DELETE FROM transfer_events WHERE ledger_sequence < ?`, cutoff); err != nil {
+		return fmt.Errorf("failed to prune transfer archive below ledger %d: %w", cutoff, err)
+	}
+	return nil
+}
+
+// TransferRecord is one archived event as returned by GetTransfers.
+type TransferRecord struct {
+	LedgerSequence uint32 `json:"ledgerSequence"`
+	ClosedAtUnix   int64  `json:"closedAtUnix"`
+	EventType      string `json:"eventType"`
+	TxHash         string `json:"txHash"`
+	AssetCode      string `json:"assetCode,omitempty"`
+	AssetIssuer    string `json:"assetIssuer,omitempty"`
+	From           string `json:"from,omitempty"`
+	To             string `json:"to,omitempty"`
+	Amount         string `json:"amount"`
+	InSuccessfulTx bool   `json:"inSuccessfulTx"`
+}
+
+// GetTransfersRequest is the decoded form of a getTransfers call.
+type GetTransfersRequest struct {
+	StartLedger uint32
+	AssetCode   string
+	AssetIssuer string
+	From        string
+	To          string
+	Cursor      string
+	Limit       int
+}
+
+// GetTransfersResponse is the result of a getTransfers call. Cursor is
+// empty once the result set has been fully paged through.
+type GetTransfersResponse struct {
+	Transfers                  []TransferRecord `json:"transfers"`
+	LatestLedger               uint32           `json:"latestLedger"`
+	LatestLedgerCloseTimestamp int64            `json:"latestLedgerCloseTimestamp"`
+	OldestLedger               uint32           `json:"oldestLedger"`
+	OldestLedgerCloseTimestamp int64            `json:"oldestLedgerCloseTimestamp"`
+	Cursor                     string           `json:"cursor,omitempty"`
+}
+
+const defaultGetTransfersLimit = 200
+
+// GetTransfers returns a page of archived transfers starting at req.Cursor
+// (or req.StartLedger if Cursor is empty), in (ledger, tx, op, event) order,
+// optionally filtered by asset, from, or to.
+func (a *Archive) GetTransfers(req GetTransfersRequest) (GetTransfersResponse, error) {
+	limit := req.Limit
+	if limit <= 0 || limit > defaultGetTransfersLimit {
+		limit = defaultGetTransfersLimit
+	}
+
+	ledger, txIndex, opIndex, eventIndex := req.StartLedger, uint32(0), uint32(0), uint32(0)
+	if req.Cursor != "" {
+		var err error
+		ledger, txIndex, opIndex, eventIndex, err = decodeCursor(req.Cursor)
+		if err != nil {
+			return GetTransfersResponse{}, err
+		}
+	}
+
+	where := []string{"(ledger_sequence, tx_index, op_index, event_index) > (?, ?, ?, ?)"}
+	args := []interface{}{ledger, txIndex, opIndex, eventIndex}
+	if req.AssetCode != "" {
+		where = append(where, "asset_code = ?")
+		args = append(args, req.AssetCode)
+	}
+	if req.AssetIssuer != "" {
+		where = append(where, "asset_issuer = ?")
+		args = append(args, req.AssetIssuer)
+	}
+	if req.From != "" {
+		where = append(where, "from_addr = ?")
+		args = append(args, req.From)
+	}
+	if req.To != "" {
+		where = append(where, "to_addr = ?")
+		args = append(args, req.To)
+	}
+	args = append(args, limit)
+
+	query := fmt.Sprintf(
+		`SELECT ledger_sequence, tx_index, op_index, event_index, closed_at_unix,
+			event_type, tx_hash, asset_code, asset_issuer, from_addr, to_addr,
+			amount, in_successful_tx
+		 FROM transfer_events
+		 WHERE %s
+		 ORDER BY ledger_sequence, tx_index, op_index, event_index
+		 LIMIT ?`,
+		strings.Join(where, " AND "),
+	)
+
+	rows, err := a.db.Query(query, args...)
+	if err != nil {
+		return GetTransfersResponse{}, fmt.Errorf("failed to query transfers: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		transfers                             []TransferRecord
+		lastLedger, lastTx, lastOp, lastEvent uint32
+		successFlag                           int
+	)
+	for rows.Next() {
+		var rec TransferRecord
+		if err := rows.Scan(
+			&lastLedger, &lastTx, &lastOp, &lastEvent, &rec.ClosedAtUnix,
+			&rec.EventType, &rec.TxHash, &rec.AssetCode, &rec.AssetIssuer,
+			&rec.From, &rec.To, &rec.Amount, &successFlag,
+		); err != nil {
+			return GetTransfersResponse{}, fmt.Errorf("failed to scan transfer row: %w", err)
+		}
+		rec.LedgerSequence = lastLedger
+		rec.InSuccessfulTx = successFlag != 0
+		transfers = append(transfers, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return GetTransfersResponse{}, fmt.Errorf("failed to read transfer rows: %w", err)
+	}
+
+	resp := GetTransfersResponse{Transfers: transfers}
+	if len(transfers) == int(limit) {
+		resp.Cursor = encodeCursor(lastLedger, lastTx, lastOp, lastEvent)
+	}
+
+	var oldestLedger, latestLedger sql.NullInt64
+	var oldestClosedAt, latestClosedAt sql.NullInt64
+	if err := a.db.QueryRow(`SELECT MIN(ledger_sequence), MIN(closed_at_unix) FROM transfer_events`).
+		Scan(&oldestLedger, &oldestClosedAt); err != nil {
+		return GetTransfersResponse{}, fmt.Errorf("failed to read oldest ledger: %w", err)
+	}
+	if err := a.db.QueryRow(`SELECT MAX(ledger_sequence), MAX(closed_at_unix) FROM transfer_events`).
+		Scan(&latestLedger, &latestClosedAt); err != nil {
+		return GetTransfersResponse{}, fmt.Errorf("failed to read latest ledger: %w", err)
+	}
+	resp.OldestLedger = uint32(oldestLedger.Int64)
+	resp.OldestLedgerCloseTimestamp = oldestClosedAt.Int64
+	resp.LatestLedger = uint32(latestLedger.Int64)
+	resp.LatestLedgerCloseTimestamp = latestClosedAt.Int64
+
+	return resp, nil
+}
+
+func archiveFields(event *ttpb.TokenTransferEvent) (eventType, from, to, assetCode, assetIssuer, amount string) {
+	switch ev := event.Event.(type) {
+	case *ttpb.TokenTransferEvent_Transfer:
+		return "transfer", ev.Transfer.From, ev.Transfer.To, ev.Transfer.AssetCode, ev.Transfer.AssetIssuer, ev.Transfer.Amount
+	case *ttpb.TokenTransferEvent_Mint:
+		return "mint", "", ev.Mint.To, ev.Mint.AssetCode, ev.Mint.AssetIssuer, ev.Mint.Amount
+	case *ttpb.TokenTransferEvent_Burn:
+		return "burn", ev.Burn.From, "", ev.Burn.AssetCode, ev.Burn.AssetIssuer, ev.Burn.Amount
+	case *ttpb.TokenTransferEvent_Clawback:
+		return "clawback", ev.Clawback.From, "", ev.Clawback.AssetCode, ev.Clawback.AssetIssuer, ev.Clawback.Amount
+	case *ttpb.TokenTransferEvent_Fee:
+		return "fee", ev.Fee.From, "", ev.Fee.AssetCode, ev.Fee.AssetIssuer, ev.Fee.Amount
+	default:
+		return "unknown", "", "", "", "", ""
+	}
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// encodeCursor packs a (ledger, tx, op, event) position into an opaque
+// base64 string so callers don't depend on its internal format.
+func encodeCursor(ledger, txIndex, opIndex, eventIndex uint32) string {
+	raw := fmt.Sprintf("%d:%d:%d:%d", ledger, txIndex, opIndex, eventIndex)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(cursor string) (ledger, txIndex, opIndex, eventIndex uint32, err error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, 0, 0, 0, fmt.Errorf("invalid cursor: %w", err)
+	}
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 4 {
+		return 0, 0, 0, 0, fmt.Errorf("invalid cursor: expected 4 components, got %d", len(parts))
+	}
+	values := make([]uint64, 4)
+	for i, p := range parts {
+		v, err := strconv.ParseUint(p, 10, 32)
+		if err != nil {
+			return 0, 0, 0, 0, fmt.Errorf("invalid cursor component %q: %w", p, err)
+		}
+		values[i] = v
+	}
+	return uint32(values[0]), uint32(values[1]), uint32(values[2]), uint32(values[3]), nil
+}
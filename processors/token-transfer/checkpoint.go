@@ -0,0 +1,65 @@
+package token_transfer
+
+import (
+	"fmt"
+
+	"github.com/withObsrvr/nebu-processor-registry/pkg/checkpoint"
+)
+
+// Checkpointer is the dependency Origin saves its progress to after every
+// ledger: the last successfully-emitted (ledger_sequence, tx_index,
+// op_index), so a restarted Origin can resume just past it instead of
+// reprocessing (and re-archiving/re-emitting duplicates for) ledgers it
+// already finished. It's satisfied directly by checkpoint.PositionStore —
+// see checkpoint.Open's file://, sqlite://, and redis:// backends.
+type Checkpointer interface {
+	LoadPosition(processor, shard string) (checkpoint.Position, error)
+	SavePosition(processor, shard string, pos checkpoint.Position) error
+}
+
+// Option configures an Origin at construction time, for settings that must
+// be in place before the first ProcessLedger call. Everything else
+// (SetArchive, SetDLQ) stays a post-construction setter, since it's safe
+// to attach any time before processing starts; WithCheckpoint is the
+// exception because resuming needs the checkpointer wired before
+// LoadCheckpoint is called.
+type Option func(*Origin)
+
+// WithCheckpoint configures Origin to save its progress to checkpointer
+// under (processor, shard) after every ledger. Construction alone does no
+// I/O; call (*Origin).LoadCheckpoint once after NewOrigin to actually seek
+// past whatever position was last saved.
+func WithCheckpoint(checkpointer Checkpointer, processor, shard string) Option {
+	return func(o *Origin) {
+		o.checkpointer = checkpointer
+		o.checkpointProcessor = processor
+		o.checkpointShard = shard
+	}
+}
+
+// LoadCheckpoint loads the last saved position from the Checkpointer
+// configured via WithCheckpoint and makes ProcessLedger skip every event
+// at or before it. It's a no-op if WithCheckpoint was never passed to
+// NewOrigin.
+func (o *Origin) LoadCheckpoint() error {
+	if o.checkpointer == nil {
+		return nil
+	}
+	pos, err := o.checkpointer.LoadPosition(o.checkpointProcessor, o.checkpointShard)
+	if err != nil {
+		return fmt.Errorf("failed to load checkpoint: %w", err)
+	}
+	o.resumeFrom = pos
+	return nil
+}
+
+// saveCheckpoint persists pos if a Checkpointer was configured.
+func (o *Origin) saveCheckpoint(pos checkpoint.Position) error {
+	if o.checkpointer == nil {
+		return nil
+	}
+	if err := o.checkpointer.SavePosition(o.checkpointProcessor, o.checkpointShard, pos); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	return nil
+}
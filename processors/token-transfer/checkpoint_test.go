@@ -0,0 +1,78 @@
+package token_transfer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/withObsrvr/nebu-processor-registry/pkg/checkpoint"
+)
+
+// fakeCheckpointer is an in-memory Checkpointer for exercising
+// WithCheckpoint/LoadCheckpoint/saveCheckpoint without a real file/sqlite/
+// redis backend.
+type fakeCheckpointer struct {
+	positions map[[2]string]checkpoint.Position
+	saveErr   error
+}
+
+func newFakeCheckpointer() *fakeCheckpointer {
+	return &fakeCheckpointer{positions: make(map[[2]string]checkpoint.Position)}
+}
+
+func (f *fakeCheckpointer) LoadPosition(processor, shard string) (checkpoint.Position, error) {
+	return f.positions[[2]string{processor, shard}], nil
+}
+
+func (f *fakeCheckpointer) SavePosition(processor, shard string, pos checkpoint.Position) error {
+	if f.saveErr != nil {
+		return f.saveErr
+	}
+	f.positions[[2]string{processor, shard}] = pos
+	return nil
+}
+
+func TestLoadCheckpoint_NoCheckpointerIsNoop(t *testing.T) {
+	o := NewOrigin("passphrase")
+	require.NoError(t, o.LoadCheckpoint())
+	assert.Equal(t, checkpoint.Position{}, o.resumeFrom)
+}
+
+func TestLoadCheckpoint_SeeksToLastSavedPosition(t *testing.T) {
+	fc := newFakeCheckpointer()
+	want := checkpoint.Position{Ledger: 100, TxIndex: 2, OpIndex: 1}
+	require.NoError(t, fc.SavePosition("stellar/token-transfer", "0", want))
+
+	o := NewOrigin("passphrase", WithCheckpoint(fc, "stellar/token-transfer", "0"))
+	require.NoError(t, o.LoadCheckpoint())
+	assert.Equal(t, want, o.resumeFrom)
+}
+
+func TestSaveCheckpoint_NoCheckpointerIsNoop(t *testing.T) {
+	o := NewOrigin("passphrase")
+	assert.NoError(t, o.saveCheckpoint(checkpoint.Position{Ledger: 5}))
+}
+
+func TestSaveCheckpoint_PersistsUnderConfiguredProcessorAndShard(t *testing.T) {
+	fc := newFakeCheckpointer()
+	o := NewOrigin("passphrase", WithCheckpoint(fc, "stellar/token-transfer", "3"))
+
+	pos := checkpoint.Position{Ledger: 42, TxIndex: 1, OpIndex: 0}
+	require.NoError(t, o.saveCheckpoint(pos))
+
+	got, err := fc.LoadPosition("stellar/token-transfer", "3")
+	require.NoError(t, err)
+	assert.Equal(t, pos, got)
+}
+
+func TestSaveCheckpoint_WrapsStoreError(t *testing.T) {
+	fc := newFakeCheckpointer()
+	fc.saveErr = errors.New("disk full")
+	o := NewOrigin("passphrase", WithCheckpoint(fc, "stellar/token-transfer", "0"))
+
+	err := o.saveCheckpoint(checkpoint.Position{Ledger: 1})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, fc.saveErr)
+}
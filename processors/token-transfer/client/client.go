@@ -0,0 +1,77 @@
+// Package client provides a typed gRPC client for the token-transfer
+// service, so downstream Go processors can consume *ttpb.TokenTransferEvent
+// directly instead of re-parsing the HTTP/NDJSON endpoint's JSON.
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	ttpb "github.com/withObsrvr/nebu-processor-registry/processors/token-transfer/proto"
+)
+
+// Client wraps a gRPC connection to a token-transfer GRPCServer.
+type Client struct {
+	conn *grpc.ClientConn
+	svc  ttpb.TokenTransferServiceClient
+}
+
+// Dial connects to a token-transfer gRPC server at addr. When no dial
+// options are given it uses
+// grpc.WithTransportCredentials(insecure.NewCredentials()); any options
+// passed replace that default entirely.
+func Dial(addr string, opts ...grpc.DialOption) (*Client, error) {
+	if len(opts) == 0 {
+		opts = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	}
+
+	conn, err := grpc.NewClient(addr, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial %s: %w", addr, err)
+	}
+
+	return &Client{conn: conn, svc: ttpb.NewTokenTransferServiceClient(conn)}, nil
+}
+
+// Close closes the underlying gRPC connection.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// StreamEvents streams token transfer events for [startLedger, endLedger].
+// The returned event channel is closed when the stream ends; at most one
+// error is sent on the error channel before it closes. Canceling ctx stops
+// the stream.
+func (c *Client) StreamEvents(ctx context.Context, startLedger, endLedger uint32) (<-chan *ttpb.TokenTransferEvent, <-chan error, error) {
+	stream, err := c.svc.StreamEvents(ctx, &ttpb.StreamRequest{
+		StartLedger: startLedger,
+		EndLedger:   endLedger,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to start stream: %w", err)
+	}
+
+	events := make(chan *ttpb.TokenTransferEvent, 256)
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer close(events)
+		defer close(errCh)
+		for {
+			event, err := stream.Recv()
+			if err != nil {
+				if err != io.EOF {
+					errCh <- err
+				}
+				return
+			}
+			events <- event
+		}
+	}()
+
+	return events, errCh, nil
+}
@@ -0,0 +1,95 @@
+// Package main provides a standalone server exposing token-transfer events
+// over both gRPC and HTTP/NDJSON, backed by the same source.LedgerSource.
+//
+// Usage:
+//
+//	# Serve gRPC on :9090 and HTTP/NDJSON on :8080
+//	token-transfer-server --rpc-url https://archive-rpc.lightsail.network
+//
+//	# Custom addresses
+//	token-transfer-server --grpc-addr :9091 --http-addr :8081
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/stellar/go-stellar-sdk/network"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/reflection"
+
+	token_transfer "github.com/withObsrvr/nebu-processor-registry/processors/token-transfer"
+	ttpb "github.com/withObsrvr/nebu-processor-registry/processors/token-transfer/proto"
+	"github.com/withObsrvr/nebu/pkg/source"
+)
+
+var (
+	rpcURL          = flag.String("rpc-url", "https://archive-rpc.lightsail.network", "Stellar RPC archive URL")
+	passphrase      = flag.String("network-passphrase", network.PublicNetworkPassphrase, "Stellar network passphrase")
+	grpcAddr        = flag.String("grpc-addr", ":9090", "gRPC listen address")
+	httpAddr        = flag.String("http-addr", ":8080", "HTTP/NDJSON listen address (compatibility shim)")
+	transferArchive = flag.String("transfer-archive", "", "SQLite path for the transfer archive backing getTransfers; empty disables it")
+)
+
+func main() {
+	flag.Parse()
+
+	src, err := source.NewRPCLedgerSource(*rpcURL)
+	if err != nil {
+		log.Fatalf("failed to create ledger source: %v", err)
+	}
+	defer src.Close()
+
+	origin := token_transfer.NewOrigin(*passphrase)
+	defer origin.Close()
+
+	httpServer := token_transfer.NewServer(src, origin)
+	grpcTransferServer := token_transfer.NewGRPCServer(src, *passphrase)
+
+	if *transferArchive != "" {
+		archive, err := token_transfer.NewArchive(*transferArchive)
+		if err != nil {
+			log.Fatalf("failed to open transfer archive: %v", err)
+		}
+		defer archive.Close()
+		httpServer.SetArchive(archive)
+		grpcTransferServer.SetArchive(archive)
+	}
+
+	mux := http.NewServeMux()
+	httpServer.RegisterRoutes(mux)
+
+	go func() {
+		log.Printf("token-transfer HTTP/NDJSON server listening on %s", *httpAddr)
+		if err := http.ListenAndServe(*httpAddr, mux); err != nil {
+			log.Fatalf("HTTP server failed: %v", err)
+		}
+	}()
+
+	lis, err := net.Listen("tcp", *grpcAddr)
+	if err != nil {
+		log.Fatalf("failed to listen on %s: %v", *grpcAddr, err)
+	}
+
+	grpcServer := grpc.NewServer(
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:    30 * time.Second,
+			Timeout: 10 * time.Second,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             10 * time.Second,
+			PermitWithoutStream: true,
+		}),
+	)
+	ttpb.RegisterTokenTransferServiceServer(grpcServer, grpcTransferServer)
+	reflection.Register(grpcServer)
+
+	log.Printf("token-transfer gRPC server listening on %s", *grpcAddr)
+	if err := grpcServer.Serve(lis); err != nil {
+		log.Fatalf("gRPC server failed: %v", err)
+	}
+}
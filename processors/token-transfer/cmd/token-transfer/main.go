@@ -9,9 +9,32 @@
 //	token-transfer --start-ledger 60200000 --end-ledger 60200100
 //	cat ledgers.xdr | token-transfer
 //	nebu fetch 60200000 60200100 | token-transfer
+//
+//	# Also persist every event into a local archive, pruned to the last
+//	# 100k ledgers, for later getTransfers lookups via token-transfer-server
+//	TRANSFER_ARCHIVE=/var/lib/nebu/transfers.db TRANSFER_RETENTION_WINDOW=100000 \
+//	  token-transfer --start-ledger 60200000 --end-ledger 60200100
+//
+//	# Export traces to a local OTel collector
+//	OTEL_EXPORTER_OTLP_ENDPOINT=localhost:4317 \
+//	  token-transfer --start-ledger 60200000 --end-ledger 60200100
+//
+//	# Resume from the last checkpoint and quarantine unprocessable events
+//	# instead of dropping them
+//	CHECKPOINT_STORE=sqlite:///var/lib/nebu/token-transfer.checkpoint.db \
+//	DLQ_PATH=/var/lib/nebu/token-transfer.dlq.jsonl DLQ_CAPACITY=1000 \
+//	  token-transfer --start-ledger 60200000 --end-ledger 60200100
 package main
 
 import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/withObsrvr/nebu-processor-registry/pkg/checkpoint"
+	"github.com/withObsrvr/nebu-processor-registry/pkg/metrics"
+	"github.com/withObsrvr/nebu-processor-registry/pkg/processor/otel"
 	token_transfer_processor "github.com/withObsrvr/nebu-processor-registry/processors/token-transfer"
 	ttpb "github.com/withObsrvr/nebu-processor-registry/processors/token-transfer/proto"
 	"github.com/withObsrvr/nebu/pkg/processor/cli"
@@ -20,13 +43,92 @@ import (
 var version = "0.3.0"
 
 func main() {
+	shutdown, err := otel.Init("token-transfer")
+	if err != nil {
+		log.Fatalf("failed to initialize tracing: %v", err)
+	}
+	defer shutdown(context.Background())
+
 	config := cli.OriginConfig{
 		Name:        "token-transfer",
 		Description: "Stream token transfer events from Stellar ledgers (transfers, mints, burns, clawbacks, fees)",
 		Version:     version,
 	}
 
+	reg := metrics.NewRegistry("token-transfer")
+
 	cli.RunProtoOriginCLI(config, func(networkPass string) cli.ProtoOriginProcessor[*ttpb.TokenTransferEvent] {
-		return token_transfer_processor.NewOrigin(networkPass)
+		var opts []token_transfer_processor.Option
+		if storeDSN := os.Getenv("CHECKPOINT_STORE"); storeDSN != "" {
+			store, err := checkpoint.Open(storeDSN)
+			if err != nil {
+				log.Fatalf("failed to open checkpoint store: %v", err)
+			}
+			positions, ok := store.(checkpoint.PositionStore)
+			if !ok {
+				log.Fatalf("checkpoint store %q does not support position checkpoints (use file://, sqlite://, or redis://)", storeDSN)
+			}
+			shard := os.Getenv("CHECKPOINT_ID")
+			if shard == "" {
+				shard = "token-transfer"
+			}
+			opts = append(opts, token_transfer_processor.WithCheckpoint(positions, "token-transfer", shard))
+		}
+
+		origin := token_transfer_processor.NewOrigin(networkPass, opts...)
+		if err := origin.LoadCheckpoint(); err != nil {
+			log.Fatalf("failed to load checkpoint: %v", err)
+		}
+
+		if archivePath := os.Getenv("TRANSFER_ARCHIVE"); archivePath != "" {
+			archive, err := token_transfer_processor.NewArchive(archivePath)
+			if err != nil {
+				log.Fatalf("failed to open transfer archive: %v", err)
+			}
+			origin.SetArchive(archive, retentionWindowFromEnv())
+		}
+
+		if dlqPath := os.Getenv("DLQ_PATH"); dlqPath != "" {
+			dlq, err := token_transfer_processor.NewDLQ(dlqPath, dlqCapacityFromEnv(), reg.DLQCounter())
+			if err != nil {
+				log.Fatalf("failed to open DLQ: %v", err)
+			}
+			origin.SetDLQ(dlq)
+		}
+
+		return origin
 	})
 }
+
+// dlqCapacityFromEnv reads DLQ_CAPACITY (number of in-memory entries); an
+// unset or invalid value falls back to a sensible default, matching
+// retentionWindowFromEnv's "ignore invalid, use a default" style.
+func dlqCapacityFromEnv() int {
+	const defaultCapacity = 1000
+	raw := os.Getenv("DLQ_CAPACITY")
+	if raw == "" {
+		return defaultCapacity
+	}
+	capacity, err := strconv.Atoi(raw)
+	if err != nil || capacity <= 0 {
+		log.Printf("token-transfer: ignoring invalid DLQ_CAPACITY %q, using %d", raw, defaultCapacity)
+		return defaultCapacity
+	}
+	return capacity
+}
+
+// retentionWindowFromEnv reads TRANSFER_RETENTION_WINDOW (in ledgers); an
+// unset or invalid value disables pruning, matching --transfer-archive's
+// own "empty disables it" default-off convention.
+func retentionWindowFromEnv() uint32 {
+	raw := os.Getenv("TRANSFER_RETENTION_WINDOW")
+	if raw == "" {
+		return 0
+	}
+	window, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil {
+		log.Printf("token-transfer: ignoring invalid TRANSFER_RETENTION_WINDOW %q: %v", raw, err)
+		return 0
+	}
+	return uint32(window)
+}
@@ -0,0 +1,122 @@
+package token_transfer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	ttpb "github.com/withObsrvr/nebu-processor-registry/processors/token-transfer/proto"
+)
+
+// Reasons an event ends up in the DLQ instead of being emitted.
+const (
+	// ReasonConversionFailed means convertEvent returned an event with no
+	// Event variant set — the SDK produced an event.Event variant the switch
+	// in convertEvent doesn't handle yet, most likely because a newer
+	// go-stellar-sdk added one.
+	ReasonConversionFailed = "conversion_failed"
+	// ReasonEmitCancelled means ctx was done before the event could be
+	// handed to the emitter.
+	ReasonEmitCancelled = "emit_cancelled"
+)
+
+// DLQEntry is one quarantined event together with why it was quarantined.
+type DLQEntry struct {
+	Event  *ttpb.TokenTransferEvent `json:"event"`
+	Reason string                   `json:"reason"`
+}
+
+// DLQ is a bounded in-memory queue of quarantined events, mirrored to an
+// append-only on-disk JSONL file so entries survive a restart and an
+// operator can inspect them (jq, grep) without a running process. Once the
+// in-memory queue is at capacity, Add evicts the oldest entry to bound
+// memory under sustained schema drift; the on-disk copy is never pruned by
+// eviction, only by an operator rotating the file.
+type DLQ struct {
+	mu      sync.Mutex
+	cap     int
+	entries []DLQEntry
+	file    *os.File
+	counter *prometheus.CounterVec
+}
+
+// NewDLQ creates a DLQ holding at most capacity entries in memory and
+// appending every Add to path (created if it doesn't exist yet). counter,
+// typically metrics.Registry.DLQCounter(), is incremented by reason on
+// every Add; pass nil to skip metrics.
+func NewDLQ(path string, capacity int, counter *prometheus.CounterVec) (*DLQ, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open DLQ file %s: %w", path, err)
+	}
+	return &DLQ{cap: capacity, file: f, counter: counter}, nil
+}
+
+// Add quarantines event with reason: appends it to the on-disk file and
+// holds it in the in-memory queue, evicting the oldest in-memory entry
+// first if the queue is already at capacity.
+func (q *DLQ) Add(event *ttpb.TokenTransferEvent, reason string) error {
+	entry := DLQEntry{Event: event, Reason: reason}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal DLQ entry: %w", err)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, err := q.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to append DLQ entry: %w", err)
+	}
+	if len(q.entries) >= q.cap {
+		q.entries = q.entries[1:]
+	}
+	q.entries = append(q.entries, entry)
+
+	if q.counter != nil {
+		q.counter.WithLabelValues(reason).Inc()
+	}
+	return nil
+}
+
+// Len returns the number of entries currently held in memory.
+func (q *DLQ) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.entries)
+}
+
+// Close closes the on-disk DLQ file.
+func (q *DLQ) Close() error {
+	return q.file.Close()
+}
+
+// ReplayDLQ re-emits every entry currently held in memory through emit, in
+// FIFO order, removing each one from the queue as it succeeds. An operator
+// calls this after fixing whatever caused the drops (e.g. patching
+// convertEvent for a new SDK event variant and redeploying) to recover
+// quarantined events without reprocessing the ledgers they came from. It
+// stops at the first error or at ctx cancellation, leaving that entry (and
+// everything after it) queued for a later retry; entries already replayed
+// remain in the on-disk file, which is append-only and not rewritten here.
+func (q *DLQ) ReplayDLQ(ctx context.Context, emit func(context.Context, *ttpb.TokenTransferEvent) error) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	i := 0
+	for ; i < len(q.entries); i++ {
+		if err := ctx.Err(); err != nil {
+			break
+		}
+		if err := emit(ctx, q.entries[i].Event); err != nil {
+			q.entries = q.entries[i:]
+			return fmt.Errorf("failed to replay DLQ entry %d: %w", i, err)
+		}
+	}
+	q.entries = q.entries[i:]
+	return nil
+}
@@ -0,0 +1,64 @@
+package token_transfer
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ttpb "github.com/withObsrvr/nebu-processor-registry/processors/token-transfer/proto"
+)
+
+func newTestDLQ(t *testing.T) *DLQ {
+	t.Helper()
+	q, err := NewDLQ(filepath.Join(t.TempDir(), "dlq.jsonl"), 2, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { q.Close() })
+	return q
+}
+
+func TestDLQ_AddEvictsOldestPastCapacity(t *testing.T) {
+	q := newTestDLQ(t)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, q.Add(&ttpb.TokenTransferEvent{}, ReasonConversionFailed))
+	}
+
+	// capacity 2: the first of the three Adds should have been evicted.
+	assert.Equal(t, 2, q.Len())
+}
+
+func TestDLQ_ReplayDLQ_DrainsOnSuccess(t *testing.T) {
+	q := newTestDLQ(t)
+	require.NoError(t, q.Add(&ttpb.TokenTransferEvent{}, ReasonConversionFailed))
+	require.NoError(t, q.Add(&ttpb.TokenTransferEvent{}, ReasonEmitCancelled))
+
+	var replayed int
+	err := q.ReplayDLQ(context.Background(), func(ctx context.Context, event *ttpb.TokenTransferEvent) error {
+		replayed++
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, replayed)
+	assert.Equal(t, 0, q.Len())
+}
+
+func TestDLQ_ReplayDLQ_StopsAtFirstErrorAndKeepsRemainder(t *testing.T) {
+	q := newTestDLQ(t)
+	require.NoError(t, q.Add(&ttpb.TokenTransferEvent{}, ReasonConversionFailed))
+	require.NoError(t, q.Add(&ttpb.TokenTransferEvent{}, ReasonConversionFailed))
+
+	wantErr := errors.New("emit failed")
+	err := q.ReplayDLQ(context.Background(), func(ctx context.Context, event *ttpb.TokenTransferEvent) error {
+		return wantErr
+	})
+
+	require.Error(t, err)
+	// The failed entry (and anything after it) must stay queued for a
+	// later retry instead of being dropped.
+	assert.Equal(t, 2, q.Len())
+}
@@ -9,7 +9,11 @@ import (
 	"github.com/stellar/go-stellar-sdk/processors/token_transfer"
 	"github.com/stellar/go-stellar-sdk/xdr"
 	"github.com/withObsrvr/nebu/pkg/processor"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
+	"github.com/withObsrvr/nebu-processor-registry/pkg/checkpoint"
+	"github.com/withObsrvr/nebu-processor-registry/pkg/processor/otel"
 	ttpb "github.com/withObsrvr/nebu-processor-registry/processors/token-transfer/proto"
 )
 
@@ -19,16 +23,64 @@ type Origin struct {
 	passphrase string
 	eventsProc *token_transfer.EventsProcessor
 	emitter    *processor.Emitter[*ttpb.TokenTransferEvent]
+	tracer     trace.Tracer
+
+	archive         *Archive
+	retentionWindow uint32
+
+	checkpointer        Checkpointer
+	checkpointProcessor string
+	checkpointShard     string
+	resumeFrom          checkpoint.Position
+
+	dlq *DLQ
 }
 
-// NewOrigin creates a new token transfer origin processor.
-// The passphrase should be the network passphrase (e.g., network.PublicNetworkPassphrase).
-func NewOrigin(passphrase string) *Origin {
-	return &Origin{
+// NewOrigin creates a new token transfer origin processor. The passphrase
+// should be the network passphrase (e.g., network.PublicNetworkPassphrase).
+// opts configures construction-time-only settings; see WithCheckpoint.
+func NewOrigin(passphrase string, opts ...Option) *Origin {
+	o := &Origin{
 		passphrase: passphrase,
 		eventsProc: token_transfer.NewEventsProcessor(passphrase),
 		emitter:    processor.NewEmitter[*ttpb.TokenTransferEvent](1024),
+		tracer:     otel.Tracer("stellar/token-transfer"),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// SetArchive installs archive as the persistent event store ProcessLedger
+// records every emitted event into, in addition to emitting it as usual. A
+// non-zero retentionWindow prunes rows older than that many ledgers behind
+// the one just processed. Passing a nil archive disables archiving.
+func (o *Origin) SetArchive(archive *Archive, retentionWindow uint32) {
+	o.archive = archive
+	o.retentionWindow = retentionWindow
+}
+
+// SetDLQ installs dlq as the dead-letter queue ProcessLedger quarantines
+// events into instead of silently dropping them, when convertEvent returns
+// an event with no Event variant set (ReasonConversionFailed) or an emit
+// is cancelled by ctx.Done()
+// (ReasonEmitCancelled). Passing a nil dlq disables quarantining (the
+// prior, silent-drop behavior).
+func (o *Origin) SetDLQ(dlq *DLQ) {
+	o.dlq = dlq
+}
+
+// ReplayDLQ re-emits every event currently held in the DLQ installed via
+// SetDLQ, in FIFO order. It's a no-op if SetDLQ was never called.
+func (o *Origin) ReplayDLQ(ctx context.Context) error {
+	if o.dlq == nil {
+		return nil
 	}
+	return o.dlq.ReplayDLQ(ctx, func(ctx context.Context, event *ttpb.TokenTransferEvent) error {
+		otel.EmitTraced(ctx, o.tracer, o.emitter, eventTypeLabel(event), event)
+		return nil
+	})
 }
 
 // Name implements processor.Processor.
@@ -161,29 +213,38 @@ func convertEvent(sdkEvent *token_transfer.TokenTransferEvent, inSuccessfulTx bo
 // ProcessLedger implements processor.Origin.
 // It extracts token transfer events from the ledger and emits them.
 func (o *Origin) ProcessLedger(ctx context.Context, ledger xdr.LedgerCloseMeta) error {
+	return otel.TraceProcessLedger(ctx, o.tracer, ledger, func(ctx context.Context) error {
+		return o.processLedger(ctx, ledger)
+	})
+}
+
+func (o *Origin) processLedger(ctx context.Context, ledger xdr.LedgerCloseMeta) error {
+	sequence := ledger.LedgerSequence()
+	if sequence < o.resumeFrom.Ledger {
+		// Every event in this ledger was already checkpointed by a prior
+		// run; skip decoding it at all rather than just re-dropping its
+		// events one by one below.
+		return nil
+	}
+
 	// Build a map of transaction hash -> success status
-	txSuccessMap := make(map[string]bool)
-	reader, err := ingest.NewLedgerTransactionReaderFromLedgerCloseMeta(o.passphrase, ledger)
+	txSuccessMap, err := o.readTxSuccessMap(ctx, ledger)
 	if err != nil {
 		return err
 	}
-	defer reader.Close()
-
-	for {
-		tx, err := reader.Read()
-		if err != nil {
-			break // End of transactions
-		}
-		txSuccessMap[tx.Result.TransactionHash.HexString()] = tx.Result.Successful()
-	}
 
 	// Extract events from the ledger using Stellar SDK
+	ctx, eventsSpan := o.tracer.Start(ctx, "token_transfer.events_from_ledger")
 	sdkEvents, err := o.eventsProc.EventsFromLedger(ledger)
+	eventsSpan.SetAttributes(attribute.Int("event_count", len(sdkEvents)))
+	eventsSpan.End()
 	if err != nil {
 		return err
 	}
 
 	// Convert SDK events to our proto events with InSuccessfulTx field
+	eventIndex := make(map[[2]uint32]uint32) // (tx_index, op_index) -> next event_index
+	lastPos := checkpoint.Position{Ledger: sequence}
 	for _, sdkEvent := range sdkEvents {
 		successful := true // Default to true
 		if sdkEvent.Meta != nil {
@@ -197,13 +258,90 @@ func (o *Origin) ProcessLedger(ctx context.Context, ledger xdr.LedgerCloseMeta)
 			continue
 		}
 
+		pos := checkpoint.Position{
+			Ledger:  sequence,
+			TxIndex: pbEvent.Meta.TransactionIndex,
+			OpIndex: pbEvent.Meta.OperationIndex,
+		}
+		if !o.resumeFrom.Less(pos) {
+			// Already emitted (and, if configured, archived) by a prior
+			// run up through o.resumeFrom; skip it to avoid a duplicate.
+			continue
+		}
+
+		if pbEvent.Event == nil {
+			// The SDK produced an event.Event variant convertEvent's
+			// switch doesn't handle (e.g. a new kind added upstream).
+			// Quarantine it instead of silently dropping it, keeping
+			// whatever Meta we could populate for the operator to
+			// inspect; an operator widens the switch and calls
+			// ReplayDLQ once a fixed build is deployed.
+			if o.dlq != nil {
+				if err := o.dlq.Add(pbEvent, ReasonConversionFailed); err != nil {
+					return err
+				}
+			}
+			lastPos = pos
+			continue
+		}
+
+		if o.archive != nil {
+			key := [2]uint32{pbEvent.Meta.TransactionIndex, pbEvent.Meta.OperationIndex}
+			idx := eventIndex[key]
+			eventIndex[key] = idx + 1
+			if err := o.archive.Record(pbEvent, key[0], key[1], idx); err != nil {
+				return err
+			}
+		}
+
 		select {
 		case <-ctx.Done():
+			if o.dlq != nil {
+				if err := o.dlq.Add(pbEvent, ReasonEmitCancelled); err != nil {
+					return err
+				}
+			}
 			return ctx.Err()
 		default:
-			o.emitter.Emit(pbEvent)
+			otel.EmitTraced(ctx, o.tracer, o.emitter, eventTypeLabel(pbEvent), pbEvent)
+			lastPos = pos
+		}
+	}
+
+	if err := o.saveCheckpoint(lastPos); err != nil {
+		return err
+	}
+
+	if o.archive != nil && o.retentionWindow > 0 {
+		if err := o.archive.Prune(sequence, o.retentionWindow); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
+
+// readTxSuccessMap decodes the ledger's transaction results into a
+// tx-hash -> success map, in its own child span so XDR decoding time is
+// visible separately from event extraction and emission.
+func (o *Origin) readTxSuccessMap(ctx context.Context, ledger xdr.LedgerCloseMeta) (map[string]bool, error) {
+	_, span := o.tracer.Start(ctx, "token_transfer.decode_tx_results")
+	defer span.End()
+
+	txSuccessMap := make(map[string]bool)
+	reader, err := ingest.NewLedgerTransactionReaderFromLedgerCloseMeta(o.passphrase, ledger)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	for {
+		tx, err := reader.Read()
+		if err != nil {
+			break // End of transactions
+		}
+		txSuccessMap[tx.Result.TransactionHash.HexString()] = tx.Result.Successful()
+	}
+
+	return txSuccessMap, nil
+}
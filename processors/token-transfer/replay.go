@@ -0,0 +1,74 @@
+package token_transfer
+
+import (
+	"context"
+
+	"github.com/stellar/go-stellar-sdk/ingest"
+	"github.com/stellar/go-stellar-sdk/processors/token_transfer"
+	"github.com/stellar/go-stellar-sdk/xdr"
+	"github.com/withObsrvr/nebu-processor-registry/pkg/replay"
+	"github.com/withObsrvr/nebu/pkg/source"
+
+	ttpb "github.com/withObsrvr/nebu-processor-registry/processors/token-transfer/proto"
+)
+
+// Replay drives token transfer extraction over [StartLedger, EndLedger] via
+// pkg/replay's worker pool, reorder buffer, and checkpoint, for indexer
+// bootstraps and gap repair that Origin's normal streaming ProcessLedger
+// isn't suited for (it assumes one ledger at a time, in order, from a live
+// source).
+func Replay(ctx context.Context, src source.LedgerSource, passphrase string, opts replay.Options) (<-chan *ttpb.TokenTransferEvent, <-chan error) {
+	process := func(ctx context.Context, ledger xdr.LedgerCloseMeta) ([]*ttpb.TokenTransferEvent, error) {
+		return extractLedgerEvents(passphrase, ledger)
+	}
+
+	runner := replay.NewRunner(src, opts, process)
+	return runner.Run(ctx)
+}
+
+// extractLedgerEvents extracts token transfer events from ledger using a
+// dedicated token_transfer.EventsProcessor for this call rather than
+// Origin's shared one, so Replay's worker pool can call it concurrently
+// across ledgers without assuming the SDK's EventsProcessor is safe to
+// share across goroutines the way Origin's single-streaming-goroutine use
+// of it is.
+func extractLedgerEvents(passphrase string, ledger xdr.LedgerCloseMeta) ([]*ttpb.TokenTransferEvent, error) {
+	txSuccessMap := make(map[string]bool)
+	reader, err := ingest.NewLedgerTransactionReaderFromLedgerCloseMeta(passphrase, ledger)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	for {
+		tx, err := reader.Read()
+		if err != nil {
+			break // End of transactions
+		}
+		txSuccessMap[tx.Result.TransactionHash.HexString()] = tx.Result.Successful()
+	}
+
+	eventsProc := token_transfer.NewEventsProcessor(passphrase)
+	sdkEvents, err := eventsProc.EventsFromLedger(ledger)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*ttpb.TokenTransferEvent, 0, len(sdkEvents))
+	for _, sdkEvent := range sdkEvents {
+		successful := true
+		if sdkEvent.Meta != nil {
+			if found, ok := txSuccessMap[sdkEvent.Meta.TxHash]; ok {
+				successful = found
+			}
+		}
+
+		pbEvent := convertEvent(sdkEvent, successful)
+		if pbEvent == nil {
+			continue
+		}
+		events = append(events, pbEvent)
+	}
+
+	return events, nil
+}
@@ -7,24 +7,93 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/withObsrvr/nebu-processor-registry/pkg/metrics"
 	ttpb "github.com/withObsrvr/nebu-processor-registry/processors/token-transfer/proto"
 	"github.com/withObsrvr/nebu/pkg/runtime"
 	"github.com/withObsrvr/nebu/pkg/source"
 )
 
-// Server is an HTTP server that streams token transfer events.
-// For MVP, we use HTTP with JSON streaming instead of gRPC to avoid protoc dependencies.
+// Server is an HTTP server that streams token transfer events as
+// newline-delimited JSON. It's kept alongside GRPCServer as a compatibility
+// shim for clients that can't consume a gRPC stream.
 type Server struct {
-	src    source.LedgerSource
-	origin *Origin
+	src     source.LedgerSource
+	origin  *Origin
+	archive *Archive
+	reg     *metrics.Registry
+
+	streamEventsTotal *prometheus.CounterVec
+	activeConnections prometheus.Gauge
 }
 
 // NewServer creates a new token transfer HTTP server.
 func NewServer(src source.LedgerSource, origin *Origin) *Server {
+	reg := metrics.NewRegistry("token-transfer")
+	eventsTotal, activeConnections := reg.HTTPStreamCounters()
+
 	return &Server{
-		src:    src,
-		origin: origin,
+		src:               src,
+		origin:            origin,
+		reg:               reg,
+		streamEventsTotal: eventsTotal,
+		activeConnections: activeConnections,
+	}
+}
+
+// SetArchive installs archive as the backing store for the getTransfers
+// endpoint RegisterRoutes exposes. Without one, getTransfers responds with
+// 503, since there's no archive to serve history from.
+func (s *Server) SetArchive(archive *Archive) {
+	s.archive = archive
+}
+
+// handleGetTransfers implements the getTransfers HTTP/JSON-RPC-style
+// endpoint: POST a {"startLedger":N,"pagination":{"cursor":"...","limit":N}}
+// body (plus optional "assetCode", "assetIssuer", "from", "to" filters) and
+// get back {"transfers":[...],"cursor":"..."} for the next page.
+func (s *Server) handleGetTransfers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.archive == nil {
+		http.Error(w, "transfer archive not configured", http.StatusServiceUnavailable)
+		return
 	}
+
+	var body struct {
+		StartLedger uint32 `json:"startLedger"`
+		AssetCode   string `json:"assetCode"`
+		AssetIssuer string `json:"assetIssuer"`
+		From        string `json:"from"`
+		To          string `json:"to"`
+		Pagination  struct {
+			Cursor string `json:"cursor"`
+			Limit  int    `json:"limit"`
+		} `json:"pagination"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	resp, err := s.archive.GetTransfers(GetTransfersRequest{
+		StartLedger: body.StartLedger,
+		AssetCode:   body.AssetCode,
+		AssetIssuer: body.AssetIssuer,
+		From:        body.From,
+		To:          body.To,
+		Cursor:      body.Pagination.Cursor,
+		Limit:       body.Pagination.Limit,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
 }
 
 // ServeHTTP implements http.Handler for the token transfer service.
@@ -67,6 +136,12 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Create a fresh origin for this request
 	origin := NewOrigin(s.origin.passphrase)
 	defer origin.Close()
+	if s.archive != nil {
+		origin.SetArchive(s.archive, 0) // no pruning on a historical, on-demand range fetch
+	}
+
+	s.activeConnections.Inc()
+	defer s.activeConnections.Dec()
 
 	ctx, cancel := context.WithCancel(r.Context())
 	defer cancel()
@@ -108,6 +183,7 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 			// Convert to a simpler format for JSON
 			simplified := simplifyEvent(event)
+			s.streamEventsTotal.WithLabelValues(simplified.Type).Inc()
 			if err := encoder.Encode(simplified); err != nil {
 				return // Client disconnected
 			}
@@ -198,9 +274,12 @@ func simplifyEvent(ev *ttpb.TokenTransferEvent) Event {
 	return event
 }
 
-// RegisterRoutes registers the HTTP routes for this server on the given mux.
+// RegisterRoutes registers the HTTP routes for this server on the given mux,
+// including /metrics for this server's Prometheus counters.
 func (s *Server) RegisterRoutes(mux *http.ServeMux) {
 	mux.Handle("/events", s)
+	mux.Handle("/metrics", s.reg.Handler())
+	mux.HandleFunc("/getTransfers", s.handleGetTransfers)
 }
 
 // HealthCheck returns a simple health check handler.